@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"time"
 	"vpr/pkg/rec"
+	"vpr/pkg/utils"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -14,23 +15,35 @@ func getData() {
 
 	log.Info("Start Recommender")
 	r := rec.NewRecommender(limitAliases)
+	r.ExclusionWindows = getExclusionWindows()
+	r.Prices = prices
+	r.GCPools = gcPools
+	r.JVMSignals = jvmSignals
 	r.ShowConfig()
+	r.LoadLastRun()
 
 	//1. get Pod groups sts/dep/daemonset
 	durationLimit := time.Duration(0)
 	durationUsage := time.Duration(0)
 	durationJVMUsage := time.Duration(0)
+	durationRuntimeUsage := time.Duration(0)
+	durationBatchRuns := time.Duration(0)
 	durationRecommendation := time.Duration(0)
 	timeStart := time.Now()
 
 	log.Info()
 	podGroups := r.GetPodGroups()
 	log.Info("Found ", len(podGroups), " PodGroups in ", time.Since(timeStart))
+	nodePools := r.GetNodePools()
+	sparkApps := r.GetSparkApps()
 	//2. calculate req/limit for each pod group
 	for i, podGroup := range podGroups {
+		//restrict the data to the period after the last rollout if enabled
+		r.DetectRollout(podGroup)
+
 		//get limits and requests for each pod group
 		timeLimitInfo := time.Now()
-		limits := r.GetPodGroupLimits(podGroup.Namespace, podGroup.Name, podGroup.Suffix)
+		limits := r.GetPodGroupLimits(podGroup)
 		durationLimit += time.Since(timeLimitInfo)
 
 		//get usage for each pod group
@@ -43,11 +56,30 @@ func getData() {
 		jvmUsage := r.GetPodGroupJVMUsage(podGroup.Namespace, podGroup.Name, podGroup.Suffix)
 		durationJVMUsage += time.Since(timeJVMInfo)
 
+		//get go/nodejs runtime usage for each pod group
+		timeRuntimeInfo := time.Now()
+		runtimeUsage := r.GetPodGroupRuntimeUsage(podGroup.Namespace, podGroup.Name, podGroup.Suffix)
+		durationRuntimeUsage += time.Since(timeRuntimeInfo)
+
+		//get the runs of the cron jobs and jobs
+		timeBatchInfo := time.Now()
+		batchRuns := r.GetPodGroupBatchRuns(podGroup)
+		durationBatchRuns += time.Since(timeBatchInfo)
+
 		//get recommendations for each pod group
 		timeRecInfo := time.Now()
-		recs := r.GenRecommendation(podGroup, usage, jvmUsage, limits)
+		recs := r.GenRecommendation(podGroup, usage, jvmUsage, runtimeUsage, batchRuns, limits)
 		durationRecommendation += time.Since(timeRecInfo)
 
+		//HPA-managed pod groups: gains on the replicas observed over the history and equivalent target utilization
+		r.ApplyHPA(podGroup, recs, r.GetPodGroupHPA(podGroup))
+		nodePool := r.GetPodGroupNodePool(podGroup)
+		sparkApp := sparkApps.AppOf(podGroup)
+		for i := range recs {
+			recs[i].NodePool = nodePool
+			recs[i].SparkApp = sparkApp
+		}
+
 		for _, rec := range recs {
 			log.Trace(rec)
 		}
@@ -55,18 +87,35 @@ func getData() {
 		result = append(result, recs...)
 	}
 
+	//check the recommended pods fit on the nodes
+	r.CheckNodeFit(result, nodePools)
+	//monthly savings of the gains
+	r.ApplyPrices(result)
+	r.GenCSVCostSummary(result)
+
 	// Write CSV results with sorting
 	// Sort results by GainMemReqMB in descending order
 	sort.Slice(result, func(i, j int) bool {
 		return result[i].GainMemReqMB > result[j].GainMemReqMB
 	})
 	r.GenCSVRecommendations(result)
+	r.SaveLastRun(result)
+	r.SaveRunMetadata(result)
+
+	//simulate the node count and cost before/after the recommendations
+	instanceTypes, _ := utils.ReadInstanceTypesCSVFile()
+	r.GenCSVBinPack(r.SimulateBinPack(result, instanceTypes))
 
 	// Write helm-value results with filtering the dim helm values
 	r.GenYAMLLimitRecommendations(result)
+	//Spark conf of the Spark applications
+	r.GenSparkRecommendations(result)
 	//calculate total optimization
 	cpu, mem := r.CalculateMaxOptimization(result)
+	riskCPU, riskMem := r.CalculateUnderProvisioning(result)
+
+	log.Info("VPR under-provisioning (CPU: ", riskCPU, " m Mem: ", riskMem, " GiB missing on requests) recommended as risk fixes")
 
 	timeFinal := time.Now()
-	log.Info("VPR recommendations (CPU: ", cpu, " vCPUs Mem: ", mem, " GiB optimizations) generated in ", timeFinal.Sub(timeStart), " details (Limit ", durationLimit, " Usage ", durationUsage, " JVM Usage ", durationJVMUsage, " Reco ", durationRecommendation, ")")
+	log.Info("VPR recommendations (CPU: ", cpu, " vCPUs Mem: ", mem, " GiB optimizations) generated in ", timeFinal.Sub(timeStart), " details (Limit ", durationLimit, " Usage ", durationUsage, " JVM Usage ", durationJVMUsage, " Runtime Usage ", durationRuntimeUsage, " Batch Runs ", durationBatchRuns, " Reco ", durationRecommendation, ")")
 }
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"crypto/subtle"
+	"encoding/json"
 	"flag"
 	"io"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 	"vpr/pkg/types"
@@ -27,6 +30,16 @@ var (
 	listenAddress   = flag.String("web.listen-address", ":9801", "Address to listen on for telemetry")
 	metricsPath     = "/metrics"
 	limitAliases, _ = utils.ReadLimitAliasCSVFile() //read the container limit aliases from a CSV file
+	//exclusion windows from the CSV file or added through the /exclusions API
+	exclusionWindows, _   = utils.ReadExclusionWindowsCSVFile()
+	exclusionWindowsMutex sync.RWMutex
+	prices, _             = utils.ReadPricesCSVFile()     //read the price overrides per namespace/node pool from a CSV file
+	gcPools, _            = utils.ReadGCPoolsCSVFile()    //read the GC pools mapping overriding the built-in one from a CSV file
+	jvmSignals, _         = utils.ReadJVMSignalsCSVFile() //read the JVM health signals from a CSV file
+	//a run requested while another one is in progress is done right after it
+	runMutex   sync.Mutex
+	running    bool
+	runPending bool
 )
 
 // Ready Readiness message
@@ -34,6 +47,82 @@ func Ready() string {
 	return AppName + " is ready to rock"
 }
 
+// getExclusionWindows returns a copy of the current exclusion windows
+func getExclusionWindows() []utils.ExclusionWindow {
+	exclusionWindowsMutex.RLock()
+	defer exclusionWindowsMutex.RUnlock()
+	return append([]utils.ExclusionWindow{}, exclusionWindows...)
+}
+
+// runRecommendations generates the recommendations, once more after the current run if one is in progress
+func runRecommendations() {
+	runMutex.Lock()
+	if running {
+		runPending = true
+		runMutex.Unlock()
+		return
+	}
+	running = true
+	runMutex.Unlock()
+	for {
+		getData()
+		runMutex.Lock()
+		if !runPending {
+			running = false
+			runMutex.Unlock()
+			return
+		}
+		runPending = false
+		runMutex.Unlock()
+	}
+}
+
+// authorized returns true if the request holds the bearer token of the exclusions API (disabled when no token is set)
+func authorized(r *http.Request, token string) bool {
+	if token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) == 1
+}
+
+// exclusionsHandler lists (GET) or adds (POST, JSON body) exclusion windows
+// adding a window requires the EXCLUSIONS_API_TOKEN bearer token, the window is persisted in the CSV file and the recommendations are recomputed
+func exclusionsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(getExclusionWindows())
+	case http.MethodPost:
+		if !authorized(r, utils.GetStringEnv("EXCLUSIONS_API_TOKEN", "")) {
+			http.Error(w, "adding exclusion windows requires the EXCLUSIONS_API_TOKEN bearer token", http.StatusForbidden)
+			return
+		}
+		var window utils.ExclusionWindow
+		if err := json.NewDecoder(r.Body).Decode(&window); err != nil {
+			http.Error(w, "invalid exclusion window: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := window.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		exclusionWindowsMutex.Lock()
+		defer exclusionWindowsMutex.Unlock()
+		if err := utils.AppendExclusionWindowCSVFile(window); err != nil {
+			log.Error("Exclusion window not saved ", err)
+			http.Error(w, "exclusion window not saved", http.StatusInternalServerError)
+			return
+		}
+		exclusionWindows = append(exclusionWindows, window)
+		log.Info("Exclusion window added from ", window.Start, " to ", window.End, " for namespace ", window.Namespace, " pod group ", window.PodGroup, " reason ", window.Reason)
+		go runRecommendations()
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("exclusion window added, recommendations are being recomputed\n"))
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
 func manageLogger() {
 	// Output to stdout instead of the default stderr
 	// Can be any io.Writer, see below for File example
@@ -93,6 +182,8 @@ func main() {
              </html>`))
 	})
 
+	http.HandleFunc("/exclusions", exclusionsHandler)
+
 	log.Info("Serving metrics on ", metricsPath)
 	http.Handle(metricsPath, promhttp.Handler())
 
@@ -105,7 +196,7 @@ func main() {
 	go func() {
 		log.Info("Listening on port " + *listenAddress)
 		//go routine : get Data at Startup
-		go getData()
+		go runRecommendations()
 		// Funcs are invoked in their own goroutine, asynchronously.
 		c := cron.New()
 		// Run every 10 minutes
@@ -120,6 +211,12 @@ func main() {
 		log.Warn("HOT RELOAD")
 		// Reload the limit alias configuration
 		limitAliases, _ = utils.ReadLimitAliasCSVFile() //read the container limit aliases from a CSV file
+		exclusionWindowsMutex.Lock()
+		exclusionWindows, _ = utils.ReadExclusionWindowsCSVFile()
+		exclusionWindowsMutex.Unlock()
+		prices, _ = utils.ReadPricesCSVFile()
+		gcPools, _ = utils.ReadGCPoolsCSVFile()
+		jvmSignals, _ = utils.ReadJVMSignalsCSVFile()
 		log.Info("Yaml Config Reloaded for next round")
 	}
 }
@@ -2,9 +2,12 @@ package main
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"runtime"
+	"strings"
 	"testing"
 
 	log "github.com/sirupsen/logrus"
@@ -39,3 +42,50 @@ func TestReady(t *testing.T) {
 		t.Errorf("Ready() = %q, want %q", got, want)
 	}
 }
+
+func TestExclusionsHandlerRequiresToken(t *testing.T) {
+	body := `{"start":"2025-06-01T00:00:00Z","end":"2025-06-02T00:00:00Z"}`
+	tests := []struct {
+		name   string
+		token  string
+		header string
+	}{
+		{name: "API disabled without token", token: "", header: "Bearer "},
+		{name: "Wrong token", token: "s3cr3t", header: "Bearer other"},
+		{name: "Missing token", token: "s3cr3t", header: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("EXCLUSIONS_API_TOKEN", tt.token)
+			defer os.Unsetenv("EXCLUSIONS_API_TOKEN")
+			req := httptest.NewRequest(http.MethodPost, "/exclusions", strings.NewReader(body))
+			req.Header.Set("Authorization", tt.header)
+			w := httptest.NewRecorder()
+			exclusionsHandler(w, req)
+			if w.Code != http.StatusForbidden {
+				t.Errorf("exclusionsHandler() status = %v; want %v", w.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+// metrics.go
+func TestUnderProvisioning(t *testing.T) {
+	tests := []struct {
+		name     string
+		riskFix  bool
+		gain     float64
+		expected float64
+	}{
+		{"Risk fix", true, -0.3, 0.3},
+		{"Upsize below the min or without request", false, -0.3, 0},
+		{"Gain", false, 0.5, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := underProvisioning(tt.riskFix, tt.gain); got != tt.expected {
+				t.Errorf("underProvisioning(%v, %v) = %v, want %v", tt.riskFix, tt.gain, got, tt.expected)
+			}
+		})
+	}
+}
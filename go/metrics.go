@@ -4,8 +4,10 @@ import (
 	"encoding/csv"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 	"vpr/pkg/rec"
+	"vpr/pkg/utils"
 
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
@@ -28,6 +30,11 @@ var (
 		"VPR recommendation for Mem limit in MiB",
 		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
 	)
+	recCPULimit = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "recommendation_limits_cpu_cores"),
+		"VPR recommendation for CPU limit in MilliCores (only to keep Guaranteed pods)",
+		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
+	)
 	recGainCPUReq = prometheus.NewDesc(
 		prometheus.BuildFQName(ns, "", "gain_requests_cpu_cores"),
 		"VPR gain for CPU request in MilliCores",
@@ -53,6 +60,141 @@ var (
 		"VPR JVM max after full GC for Old Gen in MiB",
 		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
 	)
+	recStepsLeft = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "step_down_steps_left"),
+		"VPR number of runs left to reach the target recommendation with gradual step-down",
+		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
+	)
+	recTargetCPUReq = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "target_requests_cpu_cores"),
+		"VPR final target for CPU request when step-down is capping the recommendation",
+		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
+	)
+	recTargetMemReq = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "target_requests_memory_bytes"),
+		"VPR final target for Mem request when step-down is capping the recommendation",
+		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
+	)
+	recUnderCPUReq = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "underprovisioning_requests_cpu_cores"),
+		"VPR missing CPU request on under-provisioned containers",
+		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
+	)
+	recUnderMemReq = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "underprovisioning_requests_memory_bytes"),
+		"VPR missing Mem request on under-provisioned containers",
+		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
+	)
+	recRiskFix = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "risk_fix"),
+		"VPR 1 if the recommendation is an upsizing of an under-provisioned container",
+		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
+	)
+	recMemSlope = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "memory_growth_bytes_per_day"),
+		"VPR robust growth per day of the memory working set",
+		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
+	)
+	recOldGenSlope = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "old_gen_after_gc_growth_bytes_per_day"),
+		"VPR JVM robust growth per day of the Old Gen after GC",
+		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
+	)
+	recLeakSuspected = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "leak_suspected"),
+		"VPR 1 if the memory growth over the history suggests a leak",
+		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
+	)
+	recCPUStartupPeak = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "cpu_startup_peak_cores"),
+		"VPR max CPU cores during the warm-up after the container starts (excluded from the recommended request)",
+		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
+	)
+	recOffHeapBudget = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "off_heap_budget_memory_bytes"),
+		"VPR JVM off-heap budget (non-heap, buffers and thread stacks) kept outside of the heap",
+		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
+	)
+	recNativeHeadroom = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "native_headroom_memory_bytes"),
+		"VPR JVM memory left outside of the recommended heap and the off-heap budget with the recommended limit",
+		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
+	)
+	recJVMHeap = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "jvm_recommended_heap_memory_bytes"),
+		"VPR JVM max heap (-Xmx) consistent with the recommended limit",
+		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
+	)
+	recGCOverhead = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "jvm_gc_overhead_percent"),
+		"VPR JVM percentage of the time spent in GC pauses over the data window",
+		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
+	)
+	recGCMaxPause = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "jvm_gc_max_pause_seconds"),
+		"VPR JVM max GC pause over the data window",
+		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
+	)
+	recJVMSignal = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "jvm_signal"),
+		"VPR JVM health signal above its threshold (e.g. allocation stalls)",
+		[]string{"namespace", "kind", "pod", "container", "alias", "signal"}, nil,
+	)
+	recCPUSkew = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "cpu_pod_skew"),
+		"VPR ratio between the highest and the median CPU percentile of the pods",
+		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
+	)
+	recMemSkew = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "memory_pod_skew"),
+		"VPR ratio between the highest and the median memory percentile of the pods",
+		[]string{"namespace", "kind", "pod", "container", "alias"}, nil,
+	)
+	recAvgReplicas = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "hpa_avg_replicas"),
+		"VPR replicas of the HPA averaged over the history",
+		[]string{"namespace", "kind", "pod", "container", "alias", "hpa"}, nil,
+	)
+	recHPASuggestedCPU = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "hpa_suggested_cpu_utilization"),
+		"VPR HPA CPU target utilization keeping the same scaling with the recommended request",
+		[]string{"namespace", "kind", "pod", "container", "alias", "hpa"}, nil,
+	)
+	recHPASuggestedMem = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "hpa_suggested_memory_utilization"),
+		"VPR HPA memory target utilization keeping the same scaling with the recommended request",
+		[]string{"namespace", "kind", "pod", "container", "alias", "hpa"}, nil,
+	)
+	recNodeFit = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "node_fit_issue"),
+		"VPR 1 if the recommended pod does not fit or takes a large fraction of the largest node",
+		[]string{"namespace", "kind", "pod", "container", "alias", "fit", "smallest_node_pool"}, nil,
+	)
+	recMonthlySavings = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "monthly_savings"),
+		"VPR monthly cost delta of the recommended request gains (negative for an upsizing)",
+		[]string{"namespace", "kind", "pod", "container", "alias", "team"}, nil,
+	)
+	costSummarySavings = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "monthly_savings_total"),
+		"VPR monthly cost delta of the recommended request gains per workload, namespace, team or overall",
+		[]string{"level", "name"}, nil,
+	)
+	binPackNodes = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "binpack_nodes"),
+		"VPR estimated node count of the bin-packing simulation before/after the recommendations",
+		[]string{"scenario", "instance_type"}, nil,
+	)
+	binPackMonthlyCost = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "binpack_monthly_cost"),
+		"VPR estimated monthly cost of the nodes of the bin-packing simulation before/after the recommendations",
+		[]string{"scenario", "instance_type"}, nil,
+	)
+	binPackAllocated = prometheus.NewDesc(
+		prometheus.BuildFQName(ns, "", "binpack_allocated_percent"),
+		"VPR share of the nodes allocatable requested in the bin-packing simulation",
+		[]string{"scenario", "instance_type", "resource"}, nil,
+	)
 	recReplicas = prometheus.NewDesc(
 		prometheus.BuildFQName(ns, "", "status_replicas"),
 		"VPR number of replicas being a sts/dep/daemonset",
@@ -70,11 +212,40 @@ type metrics struct {
 	NewCPUReqM             float64
 	NewMemReqMB            float64
 	NewMemLimitMB          float64
+	NewCPULimitM           float64
 	GainCPUReqM            float64
 	GainMemReqMB           float64
 	JVMYoungMaxAfterGCMB   float64
 	JVMStaticMemMB         float64
 	JVMOldMaxAfterFullGCMB float64
+	TargetCPUReqM          float64
+	TargetMemReqMB         float64
+	StepsLeft              int
+	RiskFix                bool
+	CPURiskFix             bool
+	MemRiskFix             bool
+	MemSlopeMBPerDay       float64
+	JVMOldGenSlopeMBPerDay float64
+	LeakSuspected          bool
+	CPUStartupPeakCores    float64
+	CPUSkew                float64
+	MemSkew                float64
+	HPA                    string
+	AvgReplicas            float64
+	HPASuggestedCPUPercent float64
+	HPASuggestedMemPercent float64
+	NodeFit                string
+	SmallestNodePool       string
+	Team                   string
+	MonthlySavings         float64
+	HasSavings             bool
+	OffHeapBudgetMB        float64
+	NativeHeadroomMB       float64
+	IsJVM                  bool
+	NewJVMXmxMB            float64
+	JVMGCOverheadPercent   float64
+	JVMGCMaxPauseMs        float64
+	JVMSignals             map[string]float64
 }
 
 func init() {
@@ -97,12 +268,40 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	ch <- recCPUReq
 	ch <- recMemReq
 	ch <- recMemLimit
+	ch <- recCPULimit
 	ch <- recGainCPUReq
 	ch <- recGainMemReq
 	ch <- recYoungMaxAfterGC
 	ch <- recStaticMem
 	ch <- recOldMaxAfterFullGC
 	ch <- recReplicas
+	ch <- recStepsLeft
+	ch <- recTargetCPUReq
+	ch <- recTargetMemReq
+	ch <- recUnderCPUReq
+	ch <- recUnderMemReq
+	ch <- recRiskFix
+	ch <- recMemSlope
+	ch <- recOldGenSlope
+	ch <- recLeakSuspected
+	ch <- recCPUStartupPeak
+	ch <- recOffHeapBudget
+	ch <- recNativeHeadroom
+	ch <- recJVMHeap
+	ch <- recGCOverhead
+	ch <- recGCMaxPause
+	ch <- recJVMSignal
+	ch <- recCPUSkew
+	ch <- recMemSkew
+	ch <- recAvgReplicas
+	ch <- recHPASuggestedCPU
+	ch <- recHPASuggestedMem
+	ch <- recNodeFit
+	ch <- recMonthlySavings
+	ch <- costSummarySavings
+	ch <- binPackNodes
+	ch <- binPackMonthlyCost
+	ch <- binPackAllocated
 }
 
 // Collect is when metrics will be collected
@@ -110,6 +309,8 @@ func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
 	startProm := time.Now()
 	log.Info("Will collect metrics")
 	e.collectPromMetrics(ch)
+	e.collectBinPackMetrics(ch)
+	e.collectCostSummaryMetrics(ch)
 	end := time.Now()
 	log.Info("Monitoring metrics collect finished in ", end.Sub(startProm))
 }
@@ -120,12 +321,88 @@ func (e *Exporter) collectPromMetrics(ch chan<- prometheus.Metric) {
 		ch <- prometheus.MustNewConstMetric(recCPUReq, prometheus.GaugeValue, c.NewCPUReqM, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
 		ch <- prometheus.MustNewConstMetric(recMemReq, prometheus.GaugeValue, c.NewMemReqMB, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
 		ch <- prometheus.MustNewConstMetric(recMemLimit, prometheus.GaugeValue, c.NewMemLimitMB, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+		ch <- prometheus.MustNewConstMetric(recCPULimit, prometheus.GaugeValue, c.NewCPULimitM, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
 		ch <- prometheus.MustNewConstMetric(recGainCPUReq, prometheus.GaugeValue, c.GainCPUReqM, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
 		ch <- prometheus.MustNewConstMetric(recGainMemReq, prometheus.GaugeValue, c.GainMemReqMB, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
 		ch <- prometheus.MustNewConstMetric(recYoungMaxAfterGC, prometheus.GaugeValue, c.JVMYoungMaxAfterGCMB, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
 		ch <- prometheus.MustNewConstMetric(recStaticMem, prometheus.GaugeValue, c.JVMStaticMemMB, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
 		ch <- prometheus.MustNewConstMetric(recOldMaxAfterFullGC, prometheus.GaugeValue, c.JVMOldMaxAfterFullGCMB, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
 		ch <- prometheus.MustNewConstMetric(recReplicas, prometheus.GaugeValue, float64(c.Replicas), c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+		ch <- prometheus.MustNewConstMetric(recStepsLeft, prometheus.GaugeValue, float64(c.StepsLeft), c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+		ch <- prometheus.MustNewConstMetric(recTargetCPUReq, prometheus.GaugeValue, c.TargetCPUReqM, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+		ch <- prometheus.MustNewConstMetric(recTargetMemReq, prometheus.GaugeValue, c.TargetMemReqMB, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+		ch <- prometheus.MustNewConstMetric(recUnderCPUReq, prometheus.GaugeValue, underProvisioning(c.CPURiskFix, c.GainCPUReqM), c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+		ch <- prometheus.MustNewConstMetric(recUnderMemReq, prometheus.GaugeValue, underProvisioning(c.MemRiskFix, c.GainMemReqMB), c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+		ch <- prometheus.MustNewConstMetric(recRiskFix, prometheus.GaugeValue, boolToFloat(c.RiskFix), c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+		ch <- prometheus.MustNewConstMetric(recMemSlope, prometheus.GaugeValue, c.MemSlopeMBPerDay, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+		ch <- prometheus.MustNewConstMetric(recOldGenSlope, prometheus.GaugeValue, c.JVMOldGenSlopeMBPerDay, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+		ch <- prometheus.MustNewConstMetric(recLeakSuspected, prometheus.GaugeValue, boolToFloat(c.LeakSuspected), c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+		ch <- prometheus.MustNewConstMetric(recCPUStartupPeak, prometheus.GaugeValue, c.CPUStartupPeakCores, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+		ch <- prometheus.MustNewConstMetric(recCPUSkew, prometheus.GaugeValue, c.CPUSkew, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+		ch <- prometheus.MustNewConstMetric(recMemSkew, prometheus.GaugeValue, c.MemSkew, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+		if c.IsJVM {
+			ch <- prometheus.MustNewConstMetric(recOffHeapBudget, prometheus.GaugeValue, c.OffHeapBudgetMB, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+			ch <- prometheus.MustNewConstMetric(recNativeHeadroom, prometheus.GaugeValue, c.NativeHeadroomMB, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+			ch <- prometheus.MustNewConstMetric(recJVMHeap, prometheus.GaugeValue, c.NewJVMXmxMB, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+			ch <- prometheus.MustNewConstMetric(recGCOverhead, prometheus.GaugeValue, c.JVMGCOverheadPercent, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+			ch <- prometheus.MustNewConstMetric(recGCMaxPause, prometheus.GaugeValue, c.JVMGCMaxPauseMs/1000.0, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias)
+		}
+		for signal, value := range c.JVMSignals {
+			ch <- prometheus.MustNewConstMetric(recJVMSignal, prometheus.GaugeValue, value, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias, signal)
+		}
+		if c.HasSavings {
+			ch <- prometheus.MustNewConstMetric(recMonthlySavings, prometheus.GaugeValue, c.MonthlySavings, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias, c.Team)
+		}
+		if c.NodeFit != "" {
+			ch <- prometheus.MustNewConstMetric(recNodeFit, prometheus.GaugeValue, boolToFloat(c.NodeFit != "ok"), c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias, c.NodeFit, c.SmallestNodePool)
+		}
+		if c.HPA != "" {
+			ch <- prometheus.MustNewConstMetric(recAvgReplicas, prometheus.GaugeValue, c.AvgReplicas, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias, c.HPA)
+			if c.HPASuggestedCPUPercent > 0 {
+				ch <- prometheus.MustNewConstMetric(recHPASuggestedCPU, prometheus.GaugeValue, c.HPASuggestedCPUPercent, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias, c.HPA)
+			}
+			if c.HPASuggestedMemPercent > 0 {
+				ch <- prometheus.MustNewConstMetric(recHPASuggestedMem, prometheus.GaugeValue, c.HPASuggestedMemPercent, c.Namespace, c.Kind, c.PodGroupName, c.ContainerName, c.LimitAlias, c.HPA)
+			}
+		}
+	}
+}
+
+func (e *Exporter) collectBinPackMetrics(ch chan<- prometheus.Metric) {
+	data, err := utils.ReadCSV(rec.OutPathCsvBinPack)
+	if err != nil {
+		log.Debug("No bin-pack simulation to collect ", err)
+		return
+	}
+	for i, line := range data {
+		// omit header line
+		if i == 0 || len(line) < 9 {
+			continue
+		}
+		nodes, _ := strconv.ParseFloat(line[2], 64)
+		cpuAllocated, _ := strconv.ParseFloat(line[5], 64)
+		memAllocated, _ := strconv.ParseFloat(line[6], 64)
+		monthlyCost, _ := strconv.ParseFloat(line[8], 64)
+		ch <- prometheus.MustNewConstMetric(binPackNodes, prometheus.GaugeValue, nodes, line[0], line[1])
+		ch <- prometheus.MustNewConstMetric(binPackMonthlyCost, prometheus.GaugeValue, monthlyCost, line[0], line[1])
+		ch <- prometheus.MustNewConstMetric(binPackAllocated, prometheus.GaugeValue, cpuAllocated, line[0], line[1], "cpu")
+		ch <- prometheus.MustNewConstMetric(binPackAllocated, prometheus.GaugeValue, memAllocated, line[0], line[1], "memory")
+	}
+}
+
+func (e *Exporter) collectCostSummaryMetrics(ch chan<- prometheus.Metric) {
+	data, err := utils.ReadCSV(rec.OutPathCsvCostSummary)
+	if err != nil {
+		log.Debug("No cost summary to collect ", err)
+		return
+	}
+	for i, line := range data {
+		// omit header line
+		if i == 0 || len(line) < 3 {
+			continue
+		}
+		savings, _ := strconv.ParseFloat(line[2], 64)
+		ch <- prometheus.MustNewConstMetric(costSummarySavings, prometheus.GaugeValue, savings, line[0], line[1])
 	}
 }
 
@@ -188,6 +465,81 @@ func createMetricsList(data [][]string) []metrics {
 				} else if j == 28 {
 					tmp, _ := strconv.ParseFloat(field, 64)
 					rec.JVMOldMaxAfterFullGCMB = tmp * 1048576.0
+				} else if j == 32 {
+					tmp, _ := strconv.ParseFloat(field, 64)
+					rec.TargetCPUReqM = tmp / 1000.0
+				} else if j == 33 {
+					tmp, _ := strconv.ParseFloat(field, 64)
+					rec.TargetMemReqMB = tmp * 1048576.0
+				} else if j == 35 {
+					rec.StepsLeft, _ = strconv.Atoi(field)
+				} else if j == 36 {
+					rec.RiskFix, _ = strconv.ParseBool(field)
+				} else if j == 39 {
+					tmp, _ := strconv.ParseFloat(field, 64)
+					rec.NewCPULimitM = tmp / 1000.0
+				} else if j == 44 {
+					tmp, _ := strconv.ParseFloat(field, 64)
+					rec.MemSlopeMBPerDay = tmp * 1048576.0
+				} else if j == 45 {
+					tmp, _ := strconv.ParseFloat(field, 64)
+					rec.JVMOldGenSlopeMBPerDay = tmp * 1048576.0
+				} else if j == 46 {
+					rec.LeakSuspected, _ = strconv.ParseBool(field)
+				} else if j == 49 {
+					tmp, _ := strconv.ParseFloat(field, 64)
+					rec.CPUStartupPeakCores = tmp / 1000.0
+				} else if j == 50 {
+					rec.CPUSkew, _ = strconv.ParseFloat(field, 64)
+				} else if j == 51 {
+					rec.MemSkew, _ = strconv.ParseFloat(field, 64)
+				} else if j == 53 && field != "" {
+					//StatefulSets split per role/ordinal are exposed as <statefulset>-<role>
+					rec.PodGroupName += "-" + field
+				} else if j == 54 {
+					rec.HPA = field
+				} else if j == 55 {
+					rec.AvgReplicas, _ = strconv.ParseFloat(field, 64)
+				} else if j == 57 {
+					rec.HPASuggestedCPUPercent, _ = strconv.ParseFloat(field, 64)
+				} else if j == 59 {
+					rec.HPASuggestedMemPercent, _ = strconv.ParseFloat(field, 64)
+				} else if j == 60 {
+					rec.NodeFit = field
+				} else if j == 61 {
+					rec.SmallestNodePool = field
+				} else if j == 63 {
+					rec.Team = field
+				} else if j == 64 {
+					rec.MonthlySavings, _ = strconv.ParseFloat(field, 64)
+					rec.HasSavings = rec.MonthlySavings != 0
+				} else if j == 65 {
+					rec.IsJVM = field != ""
+				} else if j == 66 {
+					tmp, _ := strconv.ParseFloat(field, 64)
+					rec.OffHeapBudgetMB = tmp * 1048576.0
+				} else if j == 67 {
+					tmp, _ := strconv.ParseFloat(field, 64)
+					rec.NativeHeadroomMB = tmp * 1048576.0
+				} else if j == 68 {
+					tmp, _ := strconv.ParseFloat(field, 64)
+					rec.NewJVMXmxMB = tmp * 1048576.0
+				} else if j == 74 {
+					rec.JVMGCOverheadPercent, _ = strconv.ParseFloat(field, 64)
+				} else if j == 75 {
+					rec.JVMGCMaxPauseMs, _ = strconv.ParseFloat(field, 64)
+				} else if j == 76 && field != "" {
+					//name=value separated by |
+					rec.JVMSignals = make(map[string]float64)
+					for _, signal := range strings.Split(field, "|") {
+						if nameValue := strings.SplitN(signal, "=", 2); len(nameValue) == 2 {
+							rec.JVMSignals[nameValue[0]], _ = strconv.ParseFloat(nameValue[1], 64)
+						}
+					}
+				} else if j == 84 {
+					rec.CPURiskFix, _ = strconv.ParseBool(field)
+				} else if j == 85 {
+					rec.MemRiskFix, _ = strconv.ParseBool(field)
 				}
 			}
 			container = append(container, rec)
@@ -195,3 +547,18 @@ func createMetricsList(data [][]string) []metrics {
 	}
 	return container
 }
+
+// underProvisioning returns the missing request of a risk fix (0 otherwise, as in the helm values and the summary)
+func underProvisioning(riskFix bool, gain float64) float64 {
+	if !riskFix {
+		return 0
+	}
+	return -gain
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1.0
+	}
+	return 0.0
+}
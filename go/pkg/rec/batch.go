@@ -0,0 +1,118 @@
+package rec
+
+import (
+	"time"
+	"vpr/pkg/utils"
+
+	"github.com/montanaflynn/stats"
+	"github.com/prometheus/common/model"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	//pods of each job run (kept over the data window as the pods of the finished jobs are deleted)
+	queryBatchPodJobs = `max by(namespace,pod,job_name)(label_replace(max_over_time(kube_pod_owner{namespace=~"$namespace",pod=~"$podgroup$suffix",owner_kind="Job"}[$history]), "job_name", "$1", "owner_name", "(.*)"))`
+	//peak CPU and memory of each container per job run
+	queryBatchCPUPeak = `max by(job_name,container)(max by(namespace,pod,container)(max_over_time(rate(container_cpu_usage_seconds_total{namespace=~"$namespace",pod=~"$podgroup$suffix",container!="",container!="POD"}[$interval])[$history:$interval])) * on(namespace,pod) group_left(job_name) $podjobs) * 1000`
+	queryBatchMemPeak = `max by(job_name,container)(max by(namespace,pod,container)(max_over_time(container_memory_working_set_bytes{namespace=~"$namespace",pod=~"$podgroup$suffix",container!="",container!="POD"}[$history])) * on(namespace,pod) group_left(job_name) $podjobs) / 1048576`
+	//start, completion and failure of each job run
+	queryBatchStart      = `max by(job_name)(max_over_time(kube_job_status_start_time{namespace=~"$namespace",job_name=~"$podgroup$jobsuffix"}[$history]))`
+	queryBatchCompletion = `max by(job_name)(max_over_time(kube_job_status_completion_time{namespace=~"$namespace",job_name=~"$podgroup$jobsuffix"}[$history]))`
+	queryBatchFailed     = `max by(job_name)(max_over_time(kube_job_failed{namespace=~"$namespace",job_name=~"$podgroup$jobsuffix",condition="true"}[$history]))`
+)
+
+// BatchRuns is the usage per run of the jobs of a CronJob or a Job over the data window
+type BatchRuns struct {
+	Runs            int
+	FailedRuns      int
+	MeanDurationSec float64
+	MaxDurationSec  float64
+	//peak of each run per container
+	CPUPeaksM  map[string][]float64
+	MemPeaksMB map[string][]float64
+}
+
+// isBatch returns true for the pod groups running to completion (CronJob, Job)
+func isBatch(kind string) bool {
+	return kind == cron || kind == batchJob
+}
+
+// getBatchJobSuffix returns the suffix of the job names of a pod group (a CronJob creates a job per scheduled time)
+func getBatchJobSuffix(kind string) string {
+	if kind == cron {
+		return "-\\\\d+"
+	}
+	return ""
+}
+
+// GetPodGroupBatchRuns get the runs of a CronJob or a Job with the peak usage of each run (empty for the other kinds)
+func (r *Recommender) GetPodGroupBatchRuns(podGroup PodGroup) BatchRuns {
+	result := BatchRuns{CPUPeaksM: make(map[string][]float64), MemPeaksMB: make(map[string][]float64)}
+	if !isBatch(podGroup.Kind) {
+		return result
+	}
+	window := r.usageWindowFor(podGroup.Namespace, podGroup.Name)
+	nsVars := []utils.Var{{Name: "namespace", Value: podGroup.Namespace}, {Name: "podgroup", Value: podGroup.Name}, {Name: "suffix", Value: podGroup.Suffix},
+		{Name: "jobsuffix", Value: getBatchJobSuffix(podGroup.Kind)}, {Name: "interval", Value: r.Interval.String()},
+		{Name: "history", Value: model.Duration(time.Since(window.From).Round(time.Minute)).String()}}
+	//the pod jobs query is substituted first as it holds vars itself
+	peakVars := append([]utils.Var{{Name: "podjobs", Value: queryBatchPodJobs}}, nsVars...)
+
+	result.CPUPeaksM = peaksByContainer(r.queryVector(queryBatchCPUPeak, peakVars))
+	result.MemPeaksMB = peaksByContainer(r.queryVector(queryBatchMemPeak, peakVars))
+	result.Runs, result.FailedRuns, result.MeanDurationSec, result.MaxDurationSec = batchRunsFrom(
+		r.queryVector(queryBatchStart, nsVars), r.queryVector(queryBatchCompletion, nsVars), r.queryVector(queryBatchFailed, nsVars))
+	log.Info("Batch ", podGroup.Kind, " ", podGroup.Name, " ran ", result.Runs, " times (", result.FailedRuns, " failed) max duration ", result.MaxDurationSec, " s")
+	return result
+}
+
+// peaksByContainer returns the peak of each run per container
+func peaksByContainer(peaks model.Vector) map[string][]float64 {
+	result := make(map[string][]float64)
+	for _, elem := range peaks {
+		container := string(elem.Metric["container"])
+		result[container] = append(result[container], float64(elem.Value))
+	}
+	return result
+}
+
+// batchRunsFrom returns the number of runs, failed runs and the mean and max duration of the completed runs
+func batchRunsFrom(starts, completions, failed model.Vector) (int, int, float64, float64) {
+	completionByJob := make(map[string]float64)
+	for _, elem := range completions {
+		completionByJob[string(elem.Metric["job_name"])] = float64(elem.Value)
+	}
+	completed, total, max := 0, 0.0, 0.0
+	for _, elem := range starts {
+		completion, ok := completionByJob[string(elem.Metric["job_name"])]
+		if !ok || completion < float64(elem.Value) {
+			continue
+		}
+		duration := completion - float64(elem.Value)
+		completed++
+		total += duration
+		if duration > max {
+			max = duration
+		}
+	}
+	failedRuns := 0
+	for _, elem := range failed {
+		if elem.Value > 0 {
+			failedRuns++
+		}
+	}
+	mean := 0.0
+	if completed > 0 {
+		mean = total / float64(completed)
+	}
+	return len(starts), failedRuns, mean, max
+}
+
+// peakPercentile returns the percentile of the peaks of the runs
+func peakPercentile(peaks []float64, percent float64) float64 {
+	percentile, err := stats.Percentile(peaks, percent)
+	if err != nil {
+		log.Error("Error getting Percentile ", percent, " of the run peaks err ", err)
+	}
+	return percentile
+}
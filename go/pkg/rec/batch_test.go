@@ -0,0 +1,45 @@
+package rec
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestBatchRunsFrom(t *testing.T) {
+	starts := model.Vector{
+		{Metric: model.Metric{"job_name": "report-29000000"}, Value: 1000},
+		{Metric: model.Metric{"job_name": "report-29001440"}, Value: 87400},
+		{Metric: model.Metric{"job_name": "report-29002880"}, Value: 173800},
+	}
+	completions := model.Vector{
+		{Metric: model.Metric{"job_name": "report-29000000"}, Value: 1300},
+		{Metric: model.Metric{"job_name": "report-29001440"}, Value: 87900},
+	}
+	failed := model.Vector{
+		{Metric: model.Metric{"job_name": "report-29002880"}, Value: 1},
+		{Metric: model.Metric{"job_name": "report-29001440"}, Value: 0},
+	}
+	runs, failedRuns, mean, max := batchRunsFrom(starts, completions, failed)
+	if runs != 3 || failedRuns != 1 || mean != 400 || max != 500 {
+		t.Errorf("batchRunsFrom() = %v %v %v %v; want 3 1 400 500", runs, failedRuns, mean, max)
+	}
+}
+
+func TestBatchPeaks(t *testing.T) {
+	peaks := peaksByContainer(model.Vector{
+		{Metric: model.Metric{"job_name": "report-1", "container": "report"}, Value: 800},
+		{Metric: model.Metric{"job_name": "report-2", "container": "report"}, Value: 1200},
+		{Metric: model.Metric{"job_name": "report-3", "container": "report"}, Value: 1000},
+		{Metric: model.Metric{"job_name": "report-1", "container": "istio-proxy"}, Value: 50},
+	})
+	if len(peaks["report"]) != 3 || len(peaks["istio-proxy"]) != 1 {
+		t.Fatalf("peaksByContainer() = %v; want 3 report and 1 istio-proxy peaks", peaks)
+	}
+	if percentile := peakPercentile(peaks["report"], 90); percentile != 1100 {
+		t.Errorf("peakPercentile() = %v; want 1100", percentile)
+	}
+	if !isBatch(cron) || !isBatch(batchJob) || isBatch(dep) {
+		t.Errorf("isBatch() is only true for cron jobs and jobs")
+	}
+}
@@ -0,0 +1,174 @@
+package rec
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"vpr/pkg/types"
+	"vpr/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// OutPathCsvBinPack is the path to the CSV file with the bin-packing simulation
+	OutPathCsvBinPack = types.DataPath + "binpack_simulation.csv"
+	hoursPerMonth     = 730
+)
+
+// BinPackResult is the cheapest homogeneous node set fitting all the pods
+type BinPackResult struct {
+	Scenario     string
+	InstanceType string
+	Nodes        int
+	CPUReqM      float64
+	MemReqMB     float64
+	//share of the nodes allocatable requested
+	CPUAllocatedPercent float64
+	MemAllocatedPercent float64
+	//requested memory per requested vCPU (CPU:memory balance of the pods)
+	GiBPerVCPU  float64
+	MonthlyCost float64
+}
+
+type binPackPod struct {
+	cpuM, memMB float64
+}
+
+// SimulateBinPack bin-packs all the pods (without daemonsets) with their current and recommended requests
+// on each instance type with first-fit-decreasing and keeps the cheapest instance type for each scenario
+func (r *Recommender) SimulateBinPack(recs []Recommendation, instanceTypes []utils.InstanceType) []BinPackResult {
+	result := []BinPackResult{}
+	if len(instanceTypes) == 0 {
+		return result
+	}
+	before, after := binPackPods(recs)
+	for _, scenario := range []struct {
+		name string
+		pods []binPackPod
+	}{{"before", before}, {"after", after}} {
+		best := BinPackResult{Scenario: scenario.name}
+		for _, instanceType := range instanceTypes {
+			nodes := firstFitDecreasing(scenario.pods, instanceType)
+			if nodes < 0 {
+				continue
+			}
+			cost := float64(nodes) * instanceType.PriceHour * hoursPerMonth
+			if best.InstanceType == "" || cost < best.MonthlyCost || (cost == best.MonthlyCost && nodes < best.Nodes) {
+				best = BinPackResult{Scenario: scenario.name, InstanceType: instanceType.Name, Nodes: nodes, MonthlyCost: cost}
+				for _, pod := range scenario.pods {
+					best.CPUReqM += pod.cpuM
+					best.MemReqMB += pod.memMB
+				}
+				if nodes > 0 {
+					best.CPUAllocatedPercent = best.CPUReqM * 100.0 / (float64(nodes) * instanceType.CPUM)
+					best.MemAllocatedPercent = best.MemReqMB * 100.0 / (float64(nodes) * instanceType.MemMB)
+				}
+				if best.CPUReqM > 0 {
+					best.GiBPerVCPU = (best.MemReqMB / 1024.0) / (best.CPUReqM / 1000.0)
+				}
+			}
+		}
+		if best.InstanceType == "" {
+			log.Warn("Bin-pack simulation ", scenario.name, ": some pods fit no instance type")
+			continue
+		}
+		result = append(result, best)
+	}
+	return result
+}
+
+// binPackPods returns the pods (one per replica) with their current and recommended requests
+// daemonsets are excluded as they run on every node whatever the node count
+func binPackPods(recs []Recommendation) ([]binPackPod, []binPackPod) {
+	type pod struct {
+		before, after binPackPod
+		replicas      int
+	}
+	pods := make(map[string]*pod)
+	keys := []string{}
+	for _, c := range recs {
+		if c.Kind == ds {
+			continue
+		}
+		key := podKey(c)
+		p, ok := pods[key]
+		if !ok {
+			replicas := c.Replicas
+			if c.AvgReplicas > 0 {
+				replicas = int(math.Ceil(c.AvgReplicas))
+			}
+			p = &pod{replicas: replicas}
+			pods[key] = p
+			keys = append(keys, key)
+		}
+		p.before.cpuM += c.CPUReqM
+		p.before.memMB += c.MemReqMB
+		p.after.cpuM += c.NewCPUReqM
+		p.after.memMB += c.NewMemReqMB
+	}
+	before, after := []binPackPod{}, []binPackPod{}
+	for _, key := range keys {
+		p := pods[key]
+		for i := 0; i < p.replicas; i++ {
+			before = append(before, p.before)
+			after = append(after, p.after)
+		}
+	}
+	return before, after
+}
+
+// firstFitDecreasing returns the number of nodes of the instance type needed for the pods, -1 if a pod fits no node
+// the pods are sorted by decreasing dominant share (largest of their CPU and memory share of a node)
+func firstFitDecreasing(pods []binPackPod, instanceType utils.InstanceType) int {
+	share := func(p binPackPod) float64 {
+		return math.Max(p.cpuM/instanceType.CPUM, p.memMB/instanceType.MemMB)
+	}
+	sorted := append([]binPackPod{}, pods...)
+	sort.SliceStable(sorted, func(i, j int) bool { return share(sorted[i]) > share(sorted[j]) })
+
+	free := []binPackPod{}
+	for _, pod := range sorted {
+		if pod.cpuM > instanceType.CPUM || pod.memMB > instanceType.MemMB {
+			return -1
+		}
+		placed := false
+		for i := range free {
+			if pod.cpuM <= free[i].cpuM && pod.memMB <= free[i].memMB {
+				free[i].cpuM -= pod.cpuM
+				free[i].memMB -= pod.memMB
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			free = append(free, binPackPod{cpuM: instanceType.CPUM - pod.cpuM, memMB: instanceType.MemMB - pod.memMB})
+		}
+	}
+	return len(free)
+}
+
+// GenCSVBinPack writes the bin-packing simulation and logs the before/after summary
+func (r *Recommender) GenCSVBinPack(result []BinPackResult) {
+	csvData := [][]string{{"Scenario", "InstanceType", "Nodes", "CPUReqM", "MemReqMB", "CPUAllocatedPercent", "MemAllocatedPercent", "GiBPerVCPU", "MonthlyCost"}}
+	for _, elem := range result {
+		csvData = append(csvData, []string{
+			elem.Scenario,
+			elem.InstanceType,
+			strconv.Itoa(elem.Nodes),
+			strconv.FormatFloat(elem.CPUReqM, 'f', 0, 64),
+			strconv.FormatFloat(elem.MemReqMB, 'f', 0, 64),
+			strconv.FormatFloat(elem.CPUAllocatedPercent, 'f', 1, 64),
+			strconv.FormatFloat(elem.MemAllocatedPercent, 'f', 1, 64),
+			strconv.FormatFloat(elem.GiBPerVCPU, 'f', 2, 64),
+			strconv.FormatFloat(elem.MonthlyCost, 'f', 0, 64),
+		})
+		log.Info("Bin-pack simulation ", elem.Scenario, ": ", elem.Nodes, " x ", elem.InstanceType, " ($", strconv.FormatFloat(elem.MonthlyCost, 'f', 0, 64), "/month) CPU ",
+			strconv.FormatFloat(elem.CPUAllocatedPercent, 'f', 1, 64), " % Mem ", strconv.FormatFloat(elem.MemAllocatedPercent, 'f', 1, 64), " % allocated, ",
+			strconv.FormatFloat(elem.GiBPerVCPU, 'f', 2, 64), " GiB/vCPU requested")
+	}
+	if len(result) == 2 {
+		log.Info("Bin-pack simulation savings: ", result[0].Nodes-result[1].Nodes, " nodes $", strconv.FormatFloat(result[0].MonthlyCost-result[1].MonthlyCost, 'f', 0, 64), "/month")
+	}
+	utils.GenCSV(OutPathCsvBinPack, csvData)
+}
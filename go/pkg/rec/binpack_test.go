@@ -0,0 +1,57 @@
+package rec
+
+import (
+	"testing"
+	"vpr/pkg/utils"
+)
+
+func TestFirstFitDecreasing(t *testing.T) {
+	node := utils.InstanceType{Name: "m5.xlarge", CPUM: 4000, MemMB: 16000, PriceHour: 0.192}
+	tests := []struct {
+		name     string
+		pods     []binPackPod
+		expected int
+	}{
+		{"No pods", []binPackPod{}, 0},
+		{"Pods fitting one node", []binPackPod{{1000, 4000}, {1000, 4000}, {2000, 8000}}, 1},
+		{"Memory bound pods", []binPackPod{{500, 9000}, {500, 9000}, {500, 9000}}, 3},
+		{"Large pods first", []binPackPod{{500, 2000}, {3000, 12000}, {500, 2000}, {3000, 12000}}, 2},
+		{"Pod larger than the node", []binPackPod{{5000, 1000}}, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := firstFitDecreasing(tt.pods, node); result != tt.expected {
+				t.Errorf("firstFitDecreasing() = %v; want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSimulateBinPack(t *testing.T) {
+	r := &Recommender{}
+	instanceTypes := []utils.InstanceType{
+		{Name: "m5.xlarge", CPUM: 4000, MemMB: 16000, PriceHour: 0.192},
+		{Name: "r5.xlarge", CPUM: 4000, MemMB: 32000, PriceHour: 0.252},
+	}
+	recs := []Recommendation{
+		{Namespace: "ns", Kind: dep, PodGroupName: "api", ContainerName: "main", Replicas: 4, CPUReqM: 1000, MemReqMB: 8000, NewCPUReqM: 500, NewMemReqMB: 3000},
+		{Namespace: "ns", Kind: dep, PodGroupName: "api", ContainerName: "sidecar", Replicas: 4, CPUReqM: 100, MemReqMB: 200, NewCPUReqM: 100, NewMemReqMB: 200},
+		{Namespace: "ns", Kind: ds, PodGroupName: "agent", ContainerName: "main", Replicas: 10, CPUReqM: 500, MemReqMB: 500, NewCPUReqM: 100, NewMemReqMB: 100},
+	}
+
+	result := r.SimulateBinPack(recs, instanceTypes)
+	if len(result) != 2 {
+		t.Fatalf("SimulateBinPack() returned %v scenarios; want 2", len(result))
+	}
+	before, after := result[0], result[1]
+	if before.Scenario != "before" || before.Nodes != 2 || before.InstanceType != "r5.xlarge" || before.CPUReqM != 4400 {
+		t.Errorf("SimulateBinPack() before = %+v; want 2 x r5.xlarge (memory bound) for 4400m", before)
+	}
+	if after.Scenario != "after" || after.Nodes != 1 || after.InstanceType != "m5.xlarge" || after.MemReqMB != 12800 {
+		t.Errorf("SimulateBinPack() after = %+v; want 1 x m5.xlarge for 12800Mi", after)
+	}
+	if before.MonthlyCost <= after.MonthlyCost {
+		t.Errorf("SimulateBinPack() monthly cost before %v after %v; want savings", before.MonthlyCost, after.MonthlyCost)
+	}
+}
@@ -0,0 +1,116 @@
+package rec
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"vpr/pkg/types"
+	"vpr/pkg/utils"
+)
+
+const (
+	// OutPathCsvCostSummary is the path to the CSV file with the monthly savings per workload, namespace and team
+	OutPathCsvCostSummary = types.DataPath + "cost_summary.csv"
+	queryPodNodes         = `count by (node)(kube_pod_info{namespace=~"$namespace",pod=~"$podgroup$suffix"})`
+)
+
+// pricing returns true if a price is set (globally or by an override)
+func (r *Recommender) pricing() bool {
+	return r.PriceVCPUHour > 0 || r.PriceGiBHour > 0 || len(r.Prices) > 0
+}
+
+// priceFor returns the price per vCPU-hour and GiB-hour of a namespace or node pool
+// a namespace override wins over a node pool override which wins over the global prices
+func (r *Recommender) priceFor(namespace, nodePool string) (float64, float64) {
+	vcpuHour, gibHour := r.PriceVCPUHour, r.PriceGiBHour
+	for _, price := range r.Prices {
+		if price.Scope == "node_pool" && nodePool != "" && price.Name == nodePool {
+			vcpuHour, gibHour = price.VCPUHour, price.GiBHour
+		}
+	}
+	for _, price := range r.Prices {
+		//^ is the start of the string, $ is the end of the string
+		if match, _ := regexp.MatchString("^"+price.Name+"$", namespace); price.Scope == "namespace" && match {
+			return price.VCPUHour, price.GiBHour
+		}
+	}
+	return vcpuHour, gibHour
+}
+
+// monthlySavings returns the monthly cost delta of the CPU and Mem request gains (negative for an upsizing)
+func monthlySavings(gainCPUReqM, gainMemReqMB, vcpuHour, gibHour float64) float64 {
+	return (gainCPUReqM/1000.0*vcpuHour + gainMemReqMB/1024.0*gibHour) * hoursPerMonth
+}
+
+// GetPodGroupNodePool returns the node pool running most of the pods of a pod group (only needed for node pool prices)
+func (r *Recommender) GetPodGroupNodePool(podGroup PodGroup) string {
+	needed := false
+	for _, price := range r.Prices {
+		needed = needed || price.Scope == "node_pool"
+	}
+	if !needed || len(r.nodePoolOf) == 0 {
+		return ""
+	}
+	nsVars := []utils.Var{{Name: "namespace", Value: podGroup.Namespace}, {Name: "podgroup", Value: podGroup.Name}, {Name: "suffix", Value: podGroup.Suffix}}
+	podsByPool := make(map[string]float64)
+	for _, elem := range r.queryVector(queryPodNodes, nsVars) {
+		podsByPool[r.nodePoolOf[string(elem.Metric["node"])]] += float64(elem.Value)
+	}
+	result, max := "", 0.0
+	for pool, pods := range podsByPool {
+		if pods > max || (pods == max && pool < result) {
+			result, max = pool, pods
+		}
+	}
+	return result
+}
+
+// ApplyPrices sets the monthly savings of each recommendation
+func (r *Recommender) ApplyPrices(recs []Recommendation) {
+	if !r.pricing() {
+		return
+	}
+	for i := range recs {
+		c := &recs[i]
+		c.PriceVCPUHour, c.PriceGiBHour = r.priceFor(c.Namespace, c.NodePool)
+		c.MonthlySavings = monthlySavings(c.GainCPUReqM, c.GainMemReqMB, c.PriceVCPUHour, c.PriceGiBHour)
+	}
+}
+
+// GenCSVCostSummary writes the monthly savings per workload, namespace and team (ranked by savings) and the total
+func (r *Recommender) GenCSVCostSummary(recs []Recommendation) {
+	if !r.pricing() {
+		return
+	}
+	type key struct{ level, name string }
+	savings := make(map[key]float64)
+	for _, c := range recs {
+		savings[key{"workload", c.Namespace + "/" + c.PodGroupName}] += c.MonthlySavings
+		savings[key{"namespace", c.Namespace}] += c.MonthlySavings
+		team := c.Team
+		if team == "" {
+			team = "NA"
+		}
+		savings[key{"team", team}] += c.MonthlySavings
+		savings[key{"total", "all"}] += c.MonthlySavings
+	}
+	keys := make([]key, 0, len(savings))
+	for k := range savings {
+		keys = append(keys, k)
+	}
+	levels := map[string]int{"total": 0, "team": 1, "namespace": 2, "workload": 3}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].level != keys[j].level {
+			return levels[keys[i].level] < levels[keys[j].level]
+		}
+		if savings[keys[i]] != savings[keys[j]] {
+			return savings[keys[i]] > savings[keys[j]]
+		}
+		return keys[i].name < keys[j].name
+	})
+	csvData := [][]string{{"Level", "Name", "MonthlySavings"}}
+	for _, k := range keys {
+		csvData = append(csvData, []string{k.level, k.name, strconv.FormatFloat(savings[k], 'f', 2, 64)})
+	}
+	utils.GenCSV(OutPathCsvCostSummary, csvData)
+}
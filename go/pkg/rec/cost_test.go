@@ -0,0 +1,50 @@
+package rec
+
+import (
+	"math"
+	"testing"
+	"vpr/pkg/utils"
+)
+
+func TestPriceFor(t *testing.T) {
+	r := &Recommender{
+		PriceVCPUHour: 0.03,
+		PriceGiBHour:  0.004,
+		Prices: []utils.Price{
+			{Scope: "node_pool", Name: "spot", VCPUHour: 0.01, GiBHour: 0.001},
+			{Scope: "namespace", Name: "gpu-.*", VCPUHour: 0.1, GiBHour: 0.01},
+		},
+	}
+	tests := []struct {
+		namespace, nodePool string
+		vcpuHour, gibHour   float64
+	}{
+		{"shop", "", 0.03, 0.004},
+		{"shop", "spot", 0.01, 0.001},
+		{"gpu-training", "spot", 0.1, 0.01},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namespace+"/"+tt.nodePool, func(t *testing.T) {
+			vcpuHour, gibHour := r.priceFor(tt.namespace, tt.nodePool)
+			if vcpuHour != tt.vcpuHour || gibHour != tt.gibHour {
+				t.Errorf("priceFor() = %v %v; want %v %v", vcpuHour, gibHour, tt.vcpuHour, tt.gibHour)
+			}
+		})
+	}
+}
+
+func TestApplyPrices(t *testing.T) {
+	recs := []Recommendation{{Namespace: "shop", GainCPUReqM: 2000, GainMemReqMB: 4096}, {Namespace: "shop", GainCPUReqM: -1000}}
+
+	(&Recommender{}).ApplyPrices(recs)
+	if recs[0].MonthlySavings != 0 {
+		t.Errorf("ApplyPrices() without prices = %v; want 0", recs[0].MonthlySavings)
+	}
+
+	(&Recommender{PriceVCPUHour: 0.03, PriceGiBHour: 0.004}).ApplyPrices(recs)
+	//(2 vCPU * 0.03 + 4 GiB * 0.004) * 730 h
+	if math.Abs(recs[0].MonthlySavings-55.48) > 0.001 || math.Abs(recs[1].MonthlySavings+21.9) > 0.001 {
+		t.Errorf("ApplyPrices() = %v %v; want 55.48 -21.9", recs[0].MonthlySavings, recs[1].MonthlySavings)
+	}
+}
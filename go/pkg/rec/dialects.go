@@ -0,0 +1,99 @@
+package rec
+
+import (
+	"vpr/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	//Micrometer dialect (Spring Boot actuator)
+	queryMicrometerYoungGenSize       = `sum by(pod,container)(jvm_memory_committed_bytes{pod=~"$podgroup$suffix",area="heap",id=~"$youngpools"})  / 1048576`
+	queryMicrometerYoungGenUsage      = `sum by(pod,container)(jvm_memory_used_bytes{pod=~"$podgroup$suffix",area="heap",id=~"$youngpools"})  / 1048576`
+	queryMicrometerOldGenUsage        = `sum by(pod,container)(jvm_memory_used_bytes{pod=~"$podgroup$suffix",area="heap",id=~"$oldpools"}) / 1048576 > 0`
+	queryMicrometerOldGenUsageAfterGC = `sum by(pod,container)(jvm_gc_live_data_size_bytes{pod=~"$podgroup$suffix"}) / 1048576 > 0` +
+		` OR (sum by(pod,container)(min_over_time(jvm_memory_used_bytes{pod=~"$podgroup$suffix",area="heap",id=~"$oldpools"}[1h]))) / 1048576 > 0`
+	queryMicrometerYoungPool        = `max by (container)(sum by (pod,container)(jvm_memory_max_bytes{pod=~"$podgroup$suffix",area="heap",id=~"$youngmaxpools"})) / 1048576  > 0`
+	queryMicrometerOldPool          = `max by (container)(sum by (pod,container)(jvm_memory_max_bytes{pod=~"$podgroup$suffix",area="heap",id=~"$oldmaxpools"})) / 1048576 > 0`
+	queryMicrometerNonHeapUsage     = `sum by(pod,container)(jvm_memory_used_bytes{pod=~"$podgroup$suffix",area="nonheap"}) / 1048576`
+	queryMicrometerBufferPoolsUsage = `sum by(pod,container)(jvm_buffer_memory_used_bytes{pod=~"$podgroup$suffix"}) / 1048576`
+	queryMicrometerGCOverhead       = `max by (container)(sum by (pod,container)(rate(jvm_gc_pause_seconds_sum{pod=~"$podgroup$suffix"}[$history]))) * 100`
+	queryMicrometerGCMaxPause       = `max by (container)(max_over_time(jvm_gc_pause_seconds_max{pod=~"$podgroup$suffix"}[$history]))`
+	queryMicrometerThreads          = `sum by(pod,container)(jvm_threads_live_threads{pod=~"$podgroup$suffix"})`
+
+	//OpenTelemetry dialect (the Prometheus exporter may add the _bytes unit suffix)
+	queryOTelYoungGenSize       = `sum by(pod,container)({__name__=~"jvm_memory_committed(_bytes)?",pod=~"$podgroup$suffix",jvm_memory_pool_name=~"$youngpools"})  / 1048576`
+	queryOTelYoungGenUsage      = `sum by(pod,container)({__name__=~"jvm_memory_used(_bytes)?",pod=~"$podgroup$suffix",jvm_memory_pool_name=~"$youngpools"})  / 1048576`
+	queryOTelOldGenUsage        = `sum by(pod,container)({__name__=~"jvm_memory_used(_bytes)?",pod=~"$podgroup$suffix",jvm_memory_pool_name=~"$oldpools"}) / 1048576 > 0`
+	queryOTelOldGenUsageAfterGC = `sum by(pod,container)({__name__=~"jvm_memory_used_after_last_gc(_bytes)?",pod=~"$podgroup$suffix",jvm_memory_pool_name=~"$oldpools"}) / 1048576 > 0` +
+		` OR (sum by(pod,container)(min_over_time({__name__=~"jvm_memory_used(_bytes)?",pod=~"$podgroup$suffix",jvm_memory_pool_name=~"$oldpools"}[1h]))) / 1048576 > 0`
+	queryOTelYoungPool        = `max by (container)(sum by (pod,container)({__name__=~"jvm_memory_limit(_bytes)?",pod=~"$podgroup$suffix",jvm_memory_pool_name=~"$youngmaxpools"})) / 1048576  > 0`
+	queryOTelOldPool          = `max by (container)(sum by (pod,container)({__name__=~"jvm_memory_limit(_bytes)?",pod=~"$podgroup$suffix",jvm_memory_pool_name=~"$oldmaxpools"})) / 1048576 > 0`
+	queryOTelNonHeapUsage     = `sum by(pod,container)({__name__=~"jvm_memory_used(_bytes)?",pod=~"$podgroup$suffix",jvm_memory_type="non_heap"}) / 1048576`
+	queryOTelBufferPoolsUsage = `sum by(pod,container)({__name__=~"jvm_buffer_memory_used(_bytes)?",pod=~"$podgroup$suffix"}) / 1048576`
+	queryOTelGCOverhead       = `max by (container)(sum by (pod,container)(rate(jvm_gc_duration_seconds_sum{pod=~"$podgroup$suffix",jvm_gc_name!~".*(Concurrent|Cycles).*"}[$history]))) * 100`
+	queryOTelGCMaxPause       = `max by (container)(max_over_time((sum by (pod,container)(rate(jvm_gc_duration_seconds_sum{pod=~"$podgroup$suffix",jvm_gc_name!~".*(Concurrent|Cycles).*"}[$interval]))` +
+		` / sum by (pod,container)(rate(jvm_gc_duration_seconds_count{pod=~"$podgroup$suffix",jvm_gc_name!~".*(Concurrent|Cycles).*"}[$interval])) > 0)[$history:$interval]))`
+	queryOTelThreads = `sum by(pod,container)(jvm_thread_count{pod=~"$podgroup$suffix"})`
+
+	//JVM dialect auto-detection
+	jvmDialectAuto = "auto"
+)
+
+// jvmDialect is a set of queries for a JVM metrics naming (exporter), all feeding JVMContainerUsage
+type jvmDialect struct {
+	Name               string
+	YoungGenSize       string
+	YoungGenUsage      string
+	OldGenUsage        string
+	OldGenUsageAfterGC string
+	YoungPool          string
+	OldPool            string
+	//instant explicit max heap ("" if the dialect only exposes the pools max)
+	HeapMax string
+	//instant GC overhead and max pause over $history
+	GCOverhead string
+	GCMaxPause string
+	//off-heap (range)
+	NonHeapUsage     string
+	BufferPoolsUsage string
+	Threads          string
+}
+
+// jvmDialects are the supported dialects in detection order
+var jvmDialects = []jvmDialect{
+	{Name: "jmx", YoungGenSize: queryYoungGenSize, YoungGenUsage: queryYoungGenUsage, OldGenUsage: queryOldGenUsage, OldGenUsageAfterGC: queryOldGenUsageAfterGC, YoungPool: queryYoungPool, OldPool: queryOldPool,
+		HeapMax: queryHeapMax, GCOverhead: queryGCOverhead, GCMaxPause: queryGCMaxPause, NonHeapUsage: queryNonHeapUsage, BufferPoolsUsage: queryBufferPoolsUsage, Threads: queryThreads},
+	{Name: "micrometer", YoungGenSize: queryMicrometerYoungGenSize, YoungGenUsage: queryMicrometerYoungGenUsage, OldGenUsage: queryMicrometerOldGenUsage, OldGenUsageAfterGC: queryMicrometerOldGenUsageAfterGC, YoungPool: queryMicrometerYoungPool, OldPool: queryMicrometerOldPool,
+		GCOverhead: queryMicrometerGCOverhead, GCMaxPause: queryMicrometerGCMaxPause, NonHeapUsage: queryMicrometerNonHeapUsage, BufferPoolsUsage: queryMicrometerBufferPoolsUsage, Threads: queryMicrometerThreads},
+	{Name: "otel", YoungGenSize: queryOTelYoungGenSize, YoungGenUsage: queryOTelYoungGenUsage, OldGenUsage: queryOTelOldGenUsage, OldGenUsageAfterGC: queryOTelOldGenUsageAfterGC, YoungPool: queryOTelYoungPool, OldPool: queryOTelOldPool,
+		GCOverhead: queryOTelGCOverhead, GCMaxPause: queryOTelGCMaxPause, NonHeapUsage: queryOTelNonHeapUsage, BufferPoolsUsage: queryOTelBufferPoolsUsage, Threads: queryOTelThreads},
+}
+
+// jvmDialectNamed returns the dialect with the given name, nil if unknown
+func jvmDialectNamed(name string) *jvmDialect {
+	for i := range jvmDialects {
+		if jvmDialects[i].Name == name {
+			return &jvmDialects[i]
+		}
+	}
+	return nil
+}
+
+// jvmDialectFor returns the configured dialect or the first one currently exposing the Old Gen usage of a pod group
+// jmx is the default when none is detected (e.g. pods not running right now), nil if the configured dialect is unknown
+func (r *Recommender) jvmDialectFor(vars []utils.Var) *jvmDialect {
+	if r.JVMDialect != "" && r.JVMDialect != jvmDialectAuto {
+		dialect := jvmDialectNamed(r.JVMDialect)
+		if dialect == nil {
+			log.Error("Unknown JVM dialect ", r.JVMDialect)
+		}
+		return dialect
+	}
+	for i := range jvmDialects {
+		if len(r.queryVector(jvmDialects[i].OldGenUsage, vars)) > 0 {
+			return &jvmDialects[i]
+		}
+	}
+	return &jvmDialects[0]
+}
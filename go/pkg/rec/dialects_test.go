@@ -0,0 +1,49 @@
+package rec
+
+import (
+	"strings"
+	"testing"
+	"vpr/pkg/utils"
+)
+
+func TestJVMDialectQueries(t *testing.T) {
+	vars := append([]utils.Var{{Name: "namespace", Value: "shop"}, {Name: "podgroup", Value: "cart"}, {Name: "suffix", Value: "-[a-z0-9]+-[a-z0-9]+"}, {Name: "interval", Value: "1m"}, {Name: "history", Value: "7d"}}, (&Recommender{}).jvmPoolVars()...)
+	for _, dialect := range jvmDialects {
+		t.Run(dialect.Name, func(t *testing.T) {
+			for _, query := range []string{dialect.YoungGenSize, dialect.YoungGenUsage, dialect.OldGenUsage, dialect.OldGenUsageAfterGC, dialect.YoungPool, dialect.OldPool, dialect.HeapMax, dialect.GCOverhead, dialect.GCMaxPause} {
+				result, err := utils.SubstVars(query, vars)
+				if err != nil {
+					t.Errorf("SubstVars(%s) err %v", query, err)
+				}
+				if strings.Contains(result, "ZGC Young Generation") != (query == dialect.YoungGenSize || query == dialect.YoungGenUsage) {
+					t.Errorf("ZGC Young Generation only expected in the young gen usage/size: %s", result)
+				}
+				//the concurrent collectors are not pauses (micrometer only records the pauses in jvm_gc_pause)
+				if (query == dialect.GCOverhead || query == dialect.GCMaxPause) && dialect.Name != "micrometer" && !strings.Contains(result, `!~".*(Concurrent|Cycles).*"`) {
+					t.Errorf("concurrent collectors expected to be excluded from the GC pauses: %s", result)
+				}
+			}
+		})
+	}
+}
+
+func TestJVMDialectFor(t *testing.T) {
+	tests := []struct {
+		dialect  string
+		expected string
+	}{
+		{"micrometer", "micrometer"},
+		{"otel", "otel"},
+		{"jmx", "jmx"},
+		{"unknown", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			result := (&Recommender{JVMDialect: tt.dialect}).jvmDialectFor(nil)
+			if (result == nil && tt.expected != "") || (result != nil && result.Name != tt.expected) {
+				t.Errorf("jvmDialectFor(%s) = %v; want %s", tt.dialect, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package rec
+
+import (
+	"regexp"
+	"strings"
+	"vpr/pkg/utils"
+)
+
+const (
+	//role of a JVM heap pool
+	gcPoolYoung = "young"
+	gcPoolOld   = "old"
+	//unified heap (non generational collector), its usage and max are accounted as the Old Gen
+	gcPoolHeap = "heap"
+)
+
+// defaultGCPools are the built-in heap pools per collector
+var defaultGCPools = []utils.GCPool{
+	{Collector: "parallel", Pool: "PS Eden Space", Role: gcPoolYoung, InMax: true},
+	{Collector: "parallel", Pool: "PS Survivor Space", Role: gcPoolYoung, InMax: true},
+	{Collector: "parallel", Pool: "PS Old Gen", Role: gcPoolOld, InMax: true},
+	{Collector: "g1", Pool: "G1 Eden Space", Role: gcPoolYoung, InMax: true},
+	{Collector: "g1", Pool: "G1 Survivor Space", Role: gcPoolYoung, InMax: true},
+	{Collector: "g1", Pool: "G1 Old Gen", Role: gcPoolOld, InMax: true},
+	{Collector: "cms", Pool: "Par Eden Space", Role: gcPoolYoung, InMax: true},
+	{Collector: "cms", Pool: "Par Survivor Space", Role: gcPoolYoung, InMax: true},
+	{Collector: "cms", Pool: "CMS Old Gen", Role: gcPoolOld, InMax: true},
+	{Collector: "serial", Pool: "Eden Space", Role: gcPoolYoung, InMax: true},
+	{Collector: "serial", Pool: "Survivor Space", Role: gcPoolYoung, InMax: true},
+	{Collector: "serial", Pool: "Tenured Gen", Role: gcPoolOld, InMax: true},
+	{Collector: "zgc", Pool: "ZHeap", Role: gcPoolHeap, InMax: true},
+	//in Java 24 max(ZGC Old Generation)=max(ZGC Young Generation)
+	{Collector: "zgc-generational", Pool: "ZGC Young Generation", Role: gcPoolYoung, InMax: false},
+	{Collector: "zgc-generational", Pool: "ZGC Old Generation", Role: gcPoolOld, InMax: true},
+	{Collector: "shenandoah", Pool: "Shenandoah", Role: gcPoolHeap, InMax: true},
+	{Collector: "epsilon", Pool: "Epsilon Heap", Role: gcPoolHeap, InMax: true},
+	{Collector: "openj9-gencon", Pool: "nursery-allocate", Role: gcPoolYoung, InMax: true},
+	{Collector: "openj9-gencon", Pool: "nursery-survivor", Role: gcPoolYoung, InMax: true},
+	{Collector: "openj9-gencon", Pool: "tenured-LOA", Role: gcPoolOld, InMax: true},
+	{Collector: "openj9-gencon", Pool: "tenured-SOA", Role: gcPoolOld, InMax: true},
+	{Collector: "openj9-balanced", Pool: "balanced-eden", Role: gcPoolYoung, InMax: true},
+	{Collector: "openj9-balanced", Pool: "balanced-survivor", Role: gcPoolYoung, InMax: true},
+	{Collector: "openj9-balanced", Pool: "balanced-old", Role: gcPoolOld, InMax: true},
+	{Collector: "openj9-optthruput", Pool: "tenured", Role: gcPoolHeap, InMax: true},
+	{Collector: "graalvm-native", Pool: "eden space", Role: gcPoolYoung, InMax: true},
+	{Collector: "graalvm-native", Pool: "survivor space", Role: gcPoolYoung, InMax: true},
+	{Collector: "graalvm-native", Pool: "old generation space", Role: gcPoolOld, InMax: true},
+	{Collector: "graalvm-native-epsilon", Pool: "epsilon heap", Role: gcPoolHeap, InMax: true},
+	{Collector: "generic", Pool: "young", Role: gcPoolYoung, InMax: true},
+	{Collector: "generic", Pool: "survivor", Role: gcPoolYoung, InMax: true},
+	{Collector: "generic", Pool: "old", Role: gcPoolOld, InMax: true},
+}
+
+// gcPools returns the built-in heap pools overridden (by pool name) or completed by the configured ones
+func (r *Recommender) gcPools() []utils.GCPool {
+	result := append([]utils.GCPool{}, defaultGCPools...)
+	for _, pool := range r.GCPools {
+		found := false
+		for i := range result {
+			if result[i].Pool == pool.Pool {
+				result[i] = pool
+				found = true
+			}
+		}
+		if !found {
+			result = append(result, pool)
+		}
+	}
+	return result
+}
+
+// gcPoolRegexes returns the regexes of the young pools (usage and max) and of the old or unified heap pools (usage and max)
+func gcPoolRegexes(pools []utils.GCPool) (young, youngMax, old, oldMax string) {
+	var youngPools, youngMaxPools, oldPools, oldMaxPools []string
+	for _, pool := range pools {
+		name := regexp.QuoteMeta(pool.Pool)
+		switch pool.Role {
+		case gcPoolYoung:
+			youngPools = append(youngPools, name)
+			if pool.InMax {
+				youngMaxPools = append(youngMaxPools, name)
+			}
+		case gcPoolOld, gcPoolHeap:
+			oldPools = append(oldPools, name)
+			if pool.InMax {
+				oldMaxPools = append(oldMaxPools, name)
+			}
+		}
+	}
+	return strings.Join(youngPools, "|"), strings.Join(youngMaxPools, "|"), strings.Join(oldPools, "|"), strings.Join(oldMaxPools, "|")
+}
+
+// jvmPoolVars returns the heap pools variables of the JVM queries (backslashes escaped for the PromQL strings)
+func (r *Recommender) jvmPoolVars() []utils.Var {
+	young, youngMax, old, oldMax := gcPoolRegexes(r.gcPools())
+	escape := strings.NewReplacer(`\`, `\\`).Replace
+	return []utils.Var{{Name: "youngpools", Value: escape(young)}, {Name: "youngmaxpools", Value: escape(youngMax)}, {Name: "oldpools", Value: escape(old)}, {Name: "oldmaxpools", Value: escape(oldMax)}}
+}
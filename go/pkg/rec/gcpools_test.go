@@ -0,0 +1,77 @@
+package rec
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"vpr/pkg/utils"
+)
+
+func TestGCPoolRegexes(t *testing.T) {
+	tests := []struct {
+		collector string
+		young     []string
+		old       []string
+		//pools in the max (Xmx%)
+		max []string
+	}{
+		{"g1", []string{"G1 Eden Space", "G1 Survivor Space"}, []string{"G1 Old Gen"}, []string{"G1 Eden Space", "G1 Survivor Space", "G1 Old Gen"}},
+		{"parallel", []string{"PS Eden Space", "PS Survivor Space"}, []string{"PS Old Gen"}, []string{"PS Eden Space", "PS Survivor Space", "PS Old Gen"}},
+		{"serial", []string{"Eden Space", "Survivor Space"}, []string{"Tenured Gen"}, []string{"Eden Space", "Survivor Space", "Tenured Gen"}},
+		{"zgc", nil, []string{"ZHeap"}, []string{"ZHeap"}},
+		{"zgc-generational", []string{"ZGC Young Generation"}, []string{"ZGC Old Generation"}, []string{"ZGC Old Generation"}},
+		{"shenandoah", nil, []string{"Shenandoah"}, []string{"Shenandoah"}},
+		{"epsilon", nil, []string{"Epsilon Heap"}, []string{"Epsilon Heap"}},
+		{"openj9-gencon", []string{"nursery-allocate", "nursery-survivor"}, []string{"tenured-LOA", "tenured-SOA"}, []string{"nursery-allocate", "nursery-survivor", "tenured-LOA", "tenured-SOA"}},
+		{"openj9-optthruput", nil, []string{"tenured"}, []string{"tenured"}},
+		{"graalvm-native", []string{"eden space", "survivor space"}, []string{"old generation space"}, []string{"eden space", "survivor space", "old generation space"}},
+	}
+
+	young, youngMax, old, oldMax := gcPoolRegexes(defaultGCPools)
+	matches := func(regex, pool string) bool { return regexp.MustCompile("^(" + regex + ")$").MatchString(pool) }
+	for _, tt := range tests {
+		t.Run(tt.collector, func(t *testing.T) {
+			pools := append(append([]string{}, tt.young...), tt.old...)
+			for _, pool := range pools {
+				if matches(young, pool) != contains(tt.young, pool) {
+					t.Errorf("pool %s young = %t; want %t", pool, matches(young, pool), contains(tt.young, pool))
+				}
+				if matches(old, pool) != contains(tt.old, pool) {
+					t.Errorf("pool %s old = %t; want %t", pool, matches(old, pool), contains(tt.old, pool))
+				}
+				if inMax := matches(youngMax, pool) || matches(oldMax, pool); inMax != contains(tt.max, pool) {
+					t.Errorf("pool %s in max = %t; want %t", pool, inMax, contains(tt.max, pool))
+				}
+			}
+		})
+	}
+}
+
+func TestGCPoolsOverride(t *testing.T) {
+	r := &Recommender{GCPools: []utils.GCPool{
+		{Collector: "custom", Pool: "tenured", Role: gcPoolOld, InMax: true},
+		{Collector: "custom", Pool: "My (Eden)", Role: gcPoolYoung, InMax: true},
+	}}
+	pools := r.gcPools()
+	if len(pools) != len(defaultGCPools)+1 {
+		t.Fatalf("gcPools() = %d pools; want %d", len(pools), len(defaultGCPools)+1)
+	}
+	young, _, old, _ := gcPoolRegexes(pools)
+	if !regexp.MustCompile("^("+young+")$").MatchString("My (Eden)") || !regexp.MustCompile("^("+old+")$").MatchString("tenured") {
+		t.Errorf("gcPoolRegexes() young %s old %s", young, old)
+	}
+	for _, v := range r.jvmPoolVars() {
+		if v.Name == "youngpools" && !strings.HasSuffix(v.Value, `|My \\(Eden\\)`) {
+			t.Errorf("jvmPoolVars() youngpools = %s; want escaped backslashes", v.Value)
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
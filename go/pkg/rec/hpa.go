@@ -0,0 +1,98 @@
+package rec
+
+import (
+	"math"
+	"vpr/pkg/utils"
+
+	"github.com/prometheus/common/model"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	queryHPAInfo         = `max by (horizontalpodautoscaler)(kube_horizontalpodautoscaler_info{namespace=~"$namespace",scaletargetref_kind="$targetkind",scaletargetref_name="$podgroup"})`
+	queryHPATargetMetric = `max by (metric_name)(kube_horizontalpodautoscaler_spec_target_metric{namespace=~"$namespace",horizontalpodautoscaler="$hpa",metric_target_type="utilization"})`
+	queryHPAAvgReplicas  = `max(avg_over_time(kube_horizontalpodautoscaler_status_current_replicas{namespace=~"$namespace",horizontalpodautoscaler="$hpa"}[$history]))`
+)
+
+// HPA is the HorizontalPodAutoscaler of a pod group
+type HPA struct {
+	Name string
+	//replicas averaged over the history
+	AvgReplicas float64
+	//target utilization (% of the request), 0 if the HPA does not scale on the resource
+	TargetCPUPercent float64
+	TargetMemPercent float64
+}
+
+// GetPodGroupHPA returns the HPA scaling a Deployment/StatefulSet, nil if there is none
+func (r *Recommender) GetPodGroupHPA(podGroup PodGroup) *HPA {
+	targetKind := map[string]string{dep: "Deployment", sts: "StatefulSet"}[podGroup.Kind]
+	if targetKind == "" {
+		return nil
+	}
+	nsVars := []utils.Var{{Name: "namespace", Value: podGroup.Namespace}, {Name: "podgroup", Value: podGroup.Name}, {Name: "targetkind", Value: targetKind}}
+	info := r.queryVector(queryHPAInfo, nsVars)
+	if len(info) == 0 {
+		return nil
+	}
+	hpa := &HPA{Name: string(info[0].Metric["horizontalpodautoscaler"])}
+	hpaVars := []utils.Var{{Name: "namespace", Value: podGroup.Namespace}, {Name: "hpa", Value: hpa.Name}, {Name: "history", Value: model.Duration(r.History).String()}}
+	for _, elem := range r.queryVector(queryHPATargetMetric, hpaVars) {
+		switch string(elem.Metric["metric_name"]) {
+		case "cpu":
+			hpa.TargetCPUPercent = float64(elem.Value)
+		case "memory":
+			hpa.TargetMemPercent = float64(elem.Value)
+		}
+	}
+	for _, elem := range r.queryVector(queryHPAAvgReplicas, hpaVars) {
+		hpa.AvgReplicas = float64(elem.Value)
+	}
+	return hpa
+}
+
+// ApplyHPA recomputes the gains with the replicas observed over the history
+// and suggests the target utilization keeping the same scaling when the HPA scales on a resized resource
+// the HPA scales on the utilization of the pod, the target is suggested on the requests summed across the containers
+// a StatefulSet split per role/ordinal keeps the gains on its pods as the replicas of the HPA cannot be attributed to a split
+func (r *Recommender) ApplyHPA(podGroup PodGroup, recs []Recommendation, hpa *HPA) {
+	if hpa == nil {
+		return
+	}
+	cpuReq, newCPUReq, memReq, newMemReq := 0.0, 0.0, 0.0, 0.0
+	for _, c := range recs {
+		cpuReq += c.CPUReqM
+		newCPUReq += c.NewCPUReqM
+		memReq += c.MemReqMB
+		newMemReq += c.NewMemReqMB
+	}
+	suggestedCPU := equivalentTarget(hpa.TargetCPUPercent, cpuReq, newCPUReq)
+	suggestedMem := equivalentTarget(hpa.TargetMemPercent, memReq, newMemReq)
+	if hpa.TargetCPUPercent > 0 && suggestedCPU != hpa.TargetCPUPercent {
+		log.Warn("HPA ", hpa.Name, " scales on CPU at ", hpa.TargetCPUPercent, "% of the requests being resized, suggested target ", suggestedCPU, "% for pod group ", podGroup.Name)
+	}
+	if hpa.TargetMemPercent > 0 && suggestedMem != hpa.TargetMemPercent {
+		log.Warn("HPA ", hpa.Name, " scales on memory at ", hpa.TargetMemPercent, "% of the requests being resized, suggested target ", suggestedMem, "% for pod group ", podGroup.Name)
+	}
+	for i := range recs {
+		c := &recs[i]
+		c.HPA = hpa.Name
+		if hpa.AvgReplicas > 0 && podGroup.Role == "" {
+			c.AvgReplicas = hpa.AvgReplicas
+			r.applyGains(c, c.AvgReplicas)
+		}
+		c.HPATargetCPUPercent = hpa.TargetCPUPercent
+		c.HPASuggestedCPUPercent = suggestedCPU
+		c.HPATargetMemPercent = hpa.TargetMemPercent
+		c.HPASuggestedMemPercent = suggestedMem
+	}
+}
+
+// equivalentTarget returns the target utilization giving the same desired replicas with the new request
+// usage / (newReq * newTarget) = usage / (req * target)
+func equivalentTarget(target, req, newReq float64) float64 {
+	if target <= 0 || req <= 0 || newReq <= 0 {
+		return target
+	}
+	return math.Round(target * req / newReq)
+}
@@ -0,0 +1,72 @@
+package rec
+
+import (
+	"testing"
+)
+
+func TestEquivalentTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   float64
+		req      float64
+		newReq   float64
+		expected float64
+	}{
+		{"No HPA target", 0, 1000, 500, 0},
+		{"Request halved doubles the target", 70, 1000, 500, 140},
+		{"Request increased lowers the target", 80, 500, 1000, 40},
+		{"No current request", 70, 0, 500, 70},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := equivalentTarget(tt.target, tt.req, tt.newReq); result != tt.expected {
+				t.Errorf("equivalentTarget(%v, %v, %v) = %v; want %v", tt.target, tt.req, tt.newReq, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApplyHPA(t *testing.T) {
+	r := &Recommender{UpsizeMinCPUReqM: 50, UpsizeMinMemReqMB: 100}
+	recs := []Recommendation{{ContainerName: "api", Replicas: 10, CPUReqM: 1000, NewCPUReqM: 400, MemReqMB: 2048, NewMemReqMB: 1024, GainCPUReqM: 6000, GainMemReqMB: 10240}}
+
+	r.ApplyHPA(PodGroup{Kind: dep, Name: "api"}, recs, nil)
+	if recs[0].HPA != "" || recs[0].GainCPUReqM != 6000 {
+		t.Errorf("ApplyHPA() without HPA changed the recommendation %v", recs[0])
+	}
+
+	r.ApplyHPA(PodGroup{Kind: dep, Name: "api"}, recs, &HPA{Name: "api", AvgReplicas: 4.5, TargetCPUPercent: 60})
+	c := recs[0]
+	if c.HPA != "api" || c.GainCPUReqM != 2700 || c.GainMemReqMB != 4608 {
+		t.Errorf("ApplyHPA() gains = %v %v; want 2700 4608 on 4.5 replicas", c.GainCPUReqM, c.GainMemReqMB)
+	}
+	if c.HPATargetCPUPercent != 60 || c.HPASuggestedCPUPercent != 150 || c.HPASuggestedMemPercent != 0 {
+		t.Errorf("ApplyHPA() targets = cpu %v -> %v mem %v; want cpu 60 -> 150 mem 0", c.HPATargetCPUPercent, c.HPASuggestedCPUPercent, c.HPASuggestedMemPercent)
+	}
+}
+
+func TestApplyHPAPodLevel(t *testing.T) {
+	r := &Recommender{UpsizeMinCPUReqM: 50, UpsizeMinMemReqMB: 100}
+	//the sidecar is not resized, the suggested target follows the requests of the pod
+	recs := []Recommendation{
+		{ContainerName: "api", Replicas: 10, CPUReqM: 900, NewCPUReqM: 400},
+		{ContainerName: "istio-proxy", Replicas: 10, CPUReqM: 100, NewCPUReqM: 100},
+	}
+	r.ApplyHPA(PodGroup{Kind: dep, Name: "api"}, recs, &HPA{Name: "api", AvgReplicas: 4, TargetCPUPercent: 50})
+	for _, c := range recs {
+		if c.HPASuggestedCPUPercent != 100 {
+			t.Errorf("ApplyHPA() suggested target = %v for container %s; want 100 for the pod", c.HPASuggestedCPUPercent, c.ContainerName)
+		}
+	}
+	if recs[0].GainCPUReqM != 2000 {
+		t.Errorf("ApplyHPA() gain = %v; want 2000 on 4 replicas", recs[0].GainCPUReqM)
+	}
+
+	//a StatefulSet split per role keeps the gains on its pods
+	split := []Recommendation{{ContainerName: "kafka", Replicas: 3, CPUReqM: 1000, NewCPUReqM: 500, GainCPUReqM: 1500}}
+	r.ApplyHPA(PodGroup{Kind: sts, Name: "kafka", Role: "broker", Count: 3}, split, &HPA{Name: "kafka", AvgReplicas: 9, TargetCPUPercent: 50})
+	if split[0].GainCPUReqM != 1500 || split[0].AvgReplicas != 0 || split[0].HPASuggestedCPUPercent != 100 {
+		t.Errorf("ApplyHPA() split = gain %v replicas %v target %v; want 1500 0 100", split[0].GainCPUReqM, split[0].AvgReplicas, split[0].HPASuggestedCPUPercent)
+	}
+}
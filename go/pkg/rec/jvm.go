@@ -12,19 +12,34 @@ import (
 )
 
 const (
+	//jmx_exporter / client_java dialect
 	//range
-	queryYoungGenSize       = `sum by(pod,container)(jvm_memory_pool_committed_bytes{pod=~"$podgroup$suffix",pool=~"PS Eden Space|G1 Eden Space|PS Survivor Space|G1 Survivor Space|Par Eden Space|Par Survivor Space|young|survivor|Eden Space|Survivor Space|ZGC Young Generation"})  / 1048576`
-	queryYoungGenUsage      = `sum by(pod,container)(jvm_memory_pool_used_bytes{pod=~"$podgroup$suffix",pool=~"PS Eden Space|G1 Eden Space|PS Survivor Space|G1 Survivor Space|Par Eden Space|Par Survivor Space|young|survivor|Eden Space|Survivor Space|ZGC Young Generation"})  / 1048576`
-	queryOldGenUsage        = `sum by(pod,container)(jvm_memory_pool_used_bytes{pod=~"$podgroup$suffix",pool=~"PS Old Gen|G1 Old Gen|CMS Old Gen|ZHeap|old|Tenured Gen|ZGC Old Generation"}) / 1048576 > 0`
-	queryOldGenUsageAfterGC = `sum by(pod,container)(jvm_memory_used_after_gc_bytes{pod=~"$podgroup$suffix",key=~"PS Old Gen|G1 Old Gen|CMS Old Gen|ZHeap|old|Tenured Gen|ZGC Old Generation"}) / 1048576 > 0` +
-		` OR (sum by(pod,container)(min_over_time(jvm_memory_pool_used_bytes{pod=~"$podgroup$suffix",pool=~"PS Old Gen|G1 Old Gen|CMS Old Gen|ZHeap|old|Tenured Gen|ZGC Old Generation"}[1h]))) / 1048576 > 0`
-	//instant to calculate XMX% = (YoungPool + OldPool) / Limit
-	//ZGC Young Generation is intentionally not included in the query because in Java 24 max(ZGC Old Generation)=max(ZGC Young Generation)
-	queryYoungPool = `max by (container)(sum by (pod,container)(jvm_memory_pool_max_bytes{pod=~"$podgroup$suffix",pool=~"PS Eden Space|G1 Eden Space|PS Survivor Space|G1 Survivor Space|Par Eden Space|Par Survivor Space|young|survivor|Eden Space|Survivor Space"})) / 1048576  > 0`
-	queryOldPool   = `max by (container)(sum by (pod,container)(jvm_memory_pool_max_bytes{pod=~"$podgroup$suffix",pool=~"PS Old Gen|G1 Old Gen|CMS Old Gen|ZHeap|old|Tenured Gen|ZGC Old Generation"})) / 1048576 > 0`
-	//only look at the last 1 day (we dont want to look for the last 7d has it would not be fair)
-	//divide by 5 because the ES query is done every 5 minutes
-	queryAllocationStall = `sum by(by_app)(sum_over_time(es_query_container_java_allocation_stall_by_host_by_app_doc_count{by_host=~"$podgroup-.*"}[1d])/5)`
+	queryYoungGenSize       = `sum by(pod,container)(jvm_memory_pool_committed_bytes{pod=~"$podgroup$suffix",pool=~"$youngpools"})  / 1048576`
+	queryYoungGenUsage      = `sum by(pod,container)(jvm_memory_pool_used_bytes{pod=~"$podgroup$suffix",pool=~"$youngpools"})  / 1048576`
+	queryOldGenUsage        = `sum by(pod,container)(jvm_memory_pool_used_bytes{pod=~"$podgroup$suffix",pool=~"$oldpools"}) / 1048576 > 0`
+	queryOldGenUsageAfterGC = `sum by(pod,container)(jvm_memory_used_after_gc_bytes{pod=~"$podgroup$suffix",key=~"$oldpools"}) / 1048576 > 0` +
+		` OR (sum by(pod,container)(min_over_time(jvm_memory_pool_used_bytes{pod=~"$podgroup$suffix",pool=~"$oldpools"}[1h]))) / 1048576 > 0`
+	//instant to calculate XMX% = (YoungPool + OldPool) / Limit (a unified heap is only in the OldPool)
+	queryYoungPool = `max by (container)(sum by (pod,container)(jvm_memory_pool_max_bytes{pod=~"$podgroup$suffix",pool=~"$youngmaxpools"})) / 1048576  > 0`
+	queryOldPool   = `max by (container)(sum by (pod,container)(jvm_memory_pool_max_bytes{pod=~"$podgroup$suffix",pool=~"$oldmaxpools"})) / 1048576 > 0`
+	//instant explicit max heap (MemoryMXBean heap max of client_java), HotSpot MaxHeapSize flag metric (configured) and JVM runtime
+	queryHeapMax     = `max by (container)(max by (pod,container)({__name__=~"jvm_memory_bytes_max|jvm_memory_max_bytes",pod=~"$podgroup$suffix",area="heap",id=""})) / 1048576 > 0`
+	queryMaxHeapFlag = `max by (container)($flagmetric{pod=~"$podgroup$suffix"}) / 1048576 > 0`
+	queryJVMInfo     = `max by (container,vendor,version)(jvm_info{pod=~"$podgroup$suffix"})`
+	//source of the Xmx%
+	jvmXmxSourceFlag    = "flag"
+	jvmXmxSourceHeapMax = "heap-max"
+	jvmXmxSourcePools   = "pools"
+	//instant GC overhead (% of the time in GC pauses) and max pause over the data window ($history)
+	//the exporter only has the pauses count and sum, the max pause is the max of the average pause per interval
+	//concurrent GC cycles (G1 Concurrent GC, ZGC/Shenandoah Cycles) are not pauses
+	queryGCOverhead = `max by (container)(sum by (pod,container)(rate(jvm_gc_collection_seconds_sum{pod=~"$podgroup$suffix",gc!~".*(Concurrent|Cycles).*"}[$history]))) * 100`
+	queryGCMaxPause = `max by (container)(max_over_time((sum by (pod,container)(rate(jvm_gc_collection_seconds_sum{pod=~"$podgroup$suffix",gc!~".*(Concurrent|Cycles).*"}[$interval]))` +
+		` / sum by (pod,container)(rate(jvm_gc_collection_seconds_count{pod=~"$podgroup$suffix",gc!~".*(Concurrent|Cycles).*"}[$interval])) > 0)[$history:$interval]))`
+	//off-heap: non-heap (metaspace, code cache...), direct/mapped buffers and threads (stacks)
+	queryNonHeapUsage     = `sum by(pod,container)({__name__=~"jvm_memory_bytes_used|jvm_memory_used_bytes",pod=~"$podgroup$suffix",area="nonheap"}) / 1048576`
+	queryBufferPoolsUsage = `sum by(pod,container)(jvm_buffer_pool_used_bytes{pod=~"$podgroup$suffix"}) / 1048576`
+	queryThreads          = `sum by(pod,container)(jvm_threads_current{pod=~"$podgroup$suffix"})`
 )
 
 type jvmPodContainerUsage struct {
@@ -46,7 +61,25 @@ type JVMContainerUsage struct {
 	OldGenUsageAfterGcMB float64
 	YoungPoolMB          float64
 	OldPoolMB            float64
-	AllocationStall      int
+	//JVM health signals (allocation stalls, humongous allocations, OOM errors...)
+	Signals []JVMSignalValue
+
+	//growth per day of the Old Gen after GC (leak detection)
+	OldGenAfterGcSlopeMBPerDay float64
+	//max off-heap usage (0 if not exposed)
+	NonHeapMB     float64
+	BufferPoolsMB float64
+	Threads       float64
+	//JVM metrics dialect (jmx, micrometer or otel)
+	Dialect string
+	//explicit max heap (0 if not exposed) and where it comes from (flag or heap-max)
+	HeapMaxMB     float64
+	HeapMaxSource string
+	//JVM version and vendor from jvm_info
+	Version string
+	//% of the time spent in GC pauses and max pause over the data window
+	GCOverheadPercent float64
+	GCMaxPauseSeconds float64
 }
 
 // JVMStats is a struct with useful stats
@@ -54,6 +87,8 @@ type JVMStats struct {
 	Min        float64
 	MaxAfterGC float64
 	Max        float64
+	//robust growth per day (Theil-Sen)
+	SlopePerDay float64
 }
 
 // GetPodGroupJVMUsage get jvm usage historical for a pod group
@@ -61,18 +96,40 @@ func (r *Recommender) GetPodGroupJVMUsage(namespace, podgroup, suffixKind string
 	result := make(map[string]JVMContainerUsage)
 	nsVars := []utils.Var{{Name: "namespace", Value: namespace}, {Name: "podgroup", Value: podgroup}, {Name: "suffix", Value: suffixKind}, {Name: "interval", Value: r.Interval.String()}}
 
-	oldGenUsageMB := r.getPodContainerJVMHistoryUsage("Old Gen", queryOldGenUsage, nsVars)
+	nsVars = append(nsVars, r.jvmPoolVars()...)
+
+	window := r.usageWindowFor(namespace, podgroup)
+
+	dialect := r.jvmDialectFor(nsVars)
+	if dialect == nil {
+		return result
+	}
+	oldGenUsageMB := r.getPodContainerJVMHistoryUsage("Old Gen", dialect.OldGenUsage, nsVars, window)
 	if len(oldGenUsageMB) == 0 {
 		log.Info("No Java Metrics available for pod group ", podgroup)
 		return result
 	}
-	youngGenUsageMB := r.getPodContainerJVMHistoryUsage("Young Gen usage", queryYoungGenUsage, nsVars)
-	oldGenUsageAfterGcMB := r.getPodContainerJVMHistoryUsage("Old Gen After GC", queryOldGenUsageAfterGC, nsVars)
-	youngGenSizeMB := r.getPodContainerJVMHistoryUsage("Young Gen", queryYoungGenSize, nsVars)
-	youngPool := r.getContainerValue(queryYoungPool, nsVars)
-	OldPool := r.getContainerValue(queryOldPool, nsVars)
-	//unfortunately today noway to know the container name (use pod name instead)
-	allocationStall := r.getPodGroupValue(queryAllocationStall, nsVars)
+	log.Info("JVM metrics dialect ", dialect.Name, " for pod group ", podgroup)
+	youngGenUsageMB := r.getPodContainerJVMHistoryUsage("Young Gen usage", dialect.YoungGenUsage, nsVars, window)
+	oldGenUsageAfterGcMB := r.getPodContainerJVMHistoryUsage("Old Gen After GC", dialect.OldGenUsageAfterGC, nsVars, window)
+	youngGenSizeMB := r.getPodContainerJVMHistoryUsage("Young Gen", dialect.YoungGenSize, nsVars, window)
+	youngPool := r.getContainerValue(dialect.YoungPool, nsVars)
+	OldPool := r.getContainerValue(dialect.OldPool, nsVars)
+	nonHeapMB := r.getPodContainerJVMHistoryUsage("Non Heap", dialect.NonHeapUsage, nsVars, window)
+	bufferPoolsMB := r.getPodContainerJVMHistoryUsage("Buffer Pools", dialect.BufferPoolsUsage, nsVars, window)
+	threads := r.getPodContainerJVMHistoryUsage("Threads", dialect.Threads, nsVars, window)
+	heapMax := []containerValue{}
+	if dialect.HeapMax != "" {
+		heapMax = r.getContainerValue(dialect.HeapMax, nsVars)
+	}
+	maxHeapFlag := []containerValue{}
+	if r.JVMMaxHeapFlagMetric != "" {
+		maxHeapFlag = r.getContainerValue(queryMaxHeapFlag, append(nsVars, utils.Var{Name: "flagmetric", Value: r.JVMMaxHeapFlagMetric}))
+	}
+	jvmInfo := r.queryVector(queryJVMInfo, nsVars)
+	gcVars := append(nsVars, utils.Var{Name: "history", Value: model.Duration(time.Since(window.From).Round(time.Minute)).String()})
+	gcOverhead := r.getContainerValue(dialect.GCOverhead, gcVars)
+	gcMaxPause := r.getContainerValue(dialect.GCMaxPause, gcVars)
 
 	for _, elem := range oldGenUsageMB {
 		result[elem.Name] = JVMContainerUsage{OldGenUsageMB: elem.Values}
@@ -88,9 +145,10 @@ func (r *Recommender) GetPodGroupJVMUsage(namespace, podgroup, suffixKind string
 	for _, elem := range oldGenUsageAfterGcMB {
 		if val, ok := result[elem.Name]; ok {
 			val.OldGenUsageAfterGcMB = elem.Values.Max
+			val.OldGenAfterGcSlopeMBPerDay = elem.Values.SlopePerDay
 			result[elem.Name] = val
 		} else {
-			result[elem.Name] = JVMContainerUsage{OldGenUsageAfterGcMB: elem.Values.Max}
+			result[elem.Name] = JVMContainerUsage{OldGenUsageAfterGcMB: elem.Values.Max, OldGenAfterGcSlopeMBPerDay: elem.Values.SlopePerDay}
 		}
 	}
 	for _, elem := range youngGenSizeMB {
@@ -117,21 +175,75 @@ func (r *Recommender) GetPodGroupJVMUsage(namespace, podgroup, suffixKind string
 			result[elem.Name] = JVMContainerUsage{OldPoolMB: elem.Value}
 		}
 	}
-	for _, elem := range allocationStall {
-		//we dont have the container name, so we use the pod group name instead
-		//we will only insert the value if the container which is matching the pod group already exists
-		for container, val := range result {
-			if strings.Contains(elem.Name, container) {
-				val.AllocationStall = int(elem.Value)
-				result[container] = val
-			}
+	//off-heap is only kept for the containers with heap metrics
+	for _, elem := range nonHeapMB {
+		if val, ok := result[elem.Name]; ok {
+			val.NonHeapMB = elem.Values.Max
+			result[elem.Name] = val
+		}
+	}
+	for _, elem := range bufferPoolsMB {
+		if val, ok := result[elem.Name]; ok {
+			val.BufferPoolsMB = elem.Values.Max
+			result[elem.Name] = val
+		}
+	}
+	for _, elem := range threads {
+		if val, ok := result[elem.Name]; ok {
+			val.Threads = elem.Values.Max
+			result[elem.Name] = val
+		}
+	}
+	//the flag wins over the heap max
+	for _, elem := range heapMax {
+		if val, ok := result[elem.Name]; ok {
+			val.HeapMaxMB, val.HeapMaxSource = elem.Value, jvmXmxSourceHeapMax
+			result[elem.Name] = val
+		}
+	}
+	for _, elem := range maxHeapFlag {
+		if val, ok := result[elem.Name]; ok {
+			val.HeapMaxMB, val.HeapMaxSource = elem.Value, jvmXmxSourceFlag
+			result[elem.Name] = val
+		}
+	}
+	for _, elem := range gcOverhead {
+		if val, ok := result[elem.Name]; ok {
+			val.GCOverheadPercent = elem.Value
+			result[elem.Name] = val
 		}
 	}
+	for _, elem := range gcMaxPause {
+		if val, ok := result[elem.Name]; ok {
+			val.GCMaxPauseSeconds = elem.Value
+			result[elem.Name] = val
+		}
+	}
+	for _, elem := range jvmInfo {
+		if val, ok := result[string(elem.Metric["container"])]; ok {
+			val.Version = strings.TrimSpace(string(elem.Metric["version"]) + " " + string(elem.Metric["vendor"]))
+			result[string(elem.Metric["container"])] = val
+		}
+	}
+	for container, val := range result {
+		val.Dialect = dialect.Name
+		result[container] = val
+	}
+	//signals are only kept for the containers with heap metrics
+	containers := make([]string, 0, len(result))
+	for container := range result {
+		containers = append(containers, container)
+	}
+	for container, signals := range r.getJVMSignals(gcVars, containers) {
+		val := result[container]
+		val.Signals = signals
+		result[container] = val
+	}
 
 	return result
 }
 
-func (r *Recommender) getPodContainerJVMHistoryUsage(name, query string, vars []utils.Var) []jvmContainerUsage {
+func (r *Recommender) getPodContainerJVMHistoryUsage(name, query string, vars []utils.Var, window usageWindow) []jvmContainerUsage {
 	result := []jvmContainerUsage{}
 	resultByPod := []jvmPodContainerUsage{}
 	query, err := utils.SubstVars(query, vars)
@@ -139,7 +251,7 @@ func (r *Recommender) getPodContainerJVMHistoryUsage(name, query string, vars []
 		log.Error("Error subst Vars:", err)
 		return result
 	}
-	data, err := utils.PromQueryRange(r.PromURL, query, vars, time.Now().Add(-r.History), time.Now(), r.Interval) //instead 5*time.Minute of r.Interval which is too long (1h)
+	data, err := utils.PromQueryRange(r.PromURL, query, vars, window.From, time.Now(), r.Interval) //instead 5*time.Minute of r.Interval which is too long (1h)
 	if err != nil {
 		log.Error("PromQL Range query wrong for ", query, " err ", err)
 	} else {
@@ -155,7 +267,12 @@ func (r *Recommender) getPodContainerJVMHistoryUsage(name, query string, vars []
 		}
 		for _, elem := range matrixVal {
 			log.Debug("Pod : ", string(elem.Metric["pod"]))
-			resultByPod = append(resultByPod, jvmPodContainerUsage{Pod: string(elem.Metric["pod"]), Container: string(elem.Metric["container"]), Values: r.GetJVMStats(elem.Values, query)})
+			samples := r.excludeSamples(elem.Values, window.Exclusions)
+			if len(samples) == 0 {
+				log.Debug("All samples excluded for pod ", string(elem.Metric["pod"]))
+				continue
+			}
+			resultByPod = append(resultByPod, jvmPodContainerUsage{Pod: string(elem.Metric["pod"]), Container: string(elem.Metric["container"]), Values: r.GetJVMStats(samples, query)})
 		}
 		result = append(result, getContainerSummary(resultByPod)...)
 		log.Debug("Result ", name, " {Min, Max}: ", result)
@@ -183,8 +300,13 @@ func getContainerSummary(pods []jvmPodContainerUsage) []jvmContainerUsage {
 				val.Values.MaxAfterGC = elem.Values.MaxAfterGC
 				mContainers[elem.Container] = val
 			}
+			//the steepest growth among the pods
+			if elem.Values.SlopePerDay > val.Values.SlopePerDay {
+				val.Values.SlopePerDay = elem.Values.SlopePerDay
+				mContainers[elem.Container] = val
+			}
 		} else {
-			mContainers[elem.Container] = jvmContainerUsage{Name: elem.Container, Values: JVMStats{Min: elem.Values.Min, MaxAfterGC: elem.Values.MaxAfterGC, Max: elem.Values.Max}}
+			mContainers[elem.Container] = jvmContainerUsage{Name: elem.Container, Values: JVMStats{Min: elem.Values.Min, MaxAfterGC: elem.Values.MaxAfterGC, Max: elem.Values.Max, SlopePerDay: elem.Values.SlopePerDay}}
 		}
 	}
 
@@ -217,12 +339,29 @@ func (r *Recommender) GetJVMStats(samples []model.SamplePair, query string) JVMS
 	// Get the max after full GC
 	maxAfterFullGC := getMaxAfterFullGC(values)
 	// log.Debug("values ", values)
-	jvmStats := JVMStats{Min: min, MaxAfterGC: maxAfterFullGC, Max: max}
+	jvmStats := JVMStats{Min: min, MaxAfterGC: maxAfterFullGC, Max: max, SlopePerDay: trendSlope(samples)}
 	log.Debug("{Min, MaxAfterFullGC, Max}: ", jvmStats)
 
 	return jvmStats
 }
 
+// gcPressureMinXmx returns the min heap under GC pressure: the current heap (grown by GCOverheadGrowPercent) above GCOverheadMaxPercent of the time in GC, 0 otherwise
+func (r *Recommender) gcPressureMinXmx(gcOverheadPercent, currentXmxMB float64) float64 {
+	if r.GCOverheadMaxPercent <= 0 || gcOverheadPercent <= r.GCOverheadMaxPercent {
+		return 0
+	}
+	return currentXmxMB * (100.0 + r.GCOverheadGrowPercent) / 100.0
+}
+
+// jvmXmxPercent returns the share of the memory limit taken by the heap and its source
+// the explicit max heap is preferred, the pools max are summed otherwise (a unified heap is only in the OldPool)
+func jvmXmxPercent(usage JVMContainerUsage, memLimitMB float64) (float64, string) {
+	if usage.HeapMaxMB > 0 {
+		return usage.HeapMaxMB * 100.0 / memLimitMB, usage.HeapMaxSource
+	}
+	return (usage.YoungPoolMB + usage.OldPoolMB) * 100.0 / memLimitMB, jvmXmxSourcePools
+}
+
 // get the min of an array of float64 excluding any values <= 0
 func getMinExcludingZeroes(values []float64) float64 {
 	min := math.MaxFloat64
@@ -251,26 +390,3 @@ func getMaxAfterFullGC(values []float64) float64 {
 
 	return maxAfterPeak
 }
-
-func (r *Recommender) getPodGroupValue(query string, vars []utils.Var) []containerValue {
-	result := []containerValue{}
-	query, err := utils.SubstVars(query, vars)
-	if err != nil {
-		log.Error("Error subst Vars:", err)
-		return result
-	}
-	data, err := utils.PromQuery(r.PromURL, query, vars)
-	if err != nil {
-		log.Error("PromQL Instant query wrong for ", query, " err ", err)
-	} else {
-		vectorVal, ok := data.(model.Vector)
-		if !ok {
-			log.Error("Error converting to Vector for query ", query)
-			return result
-		}
-		for _, elem := range vectorVal {
-			result = append(result, containerValue{Name: string(elem.Metric["by_app"]), Value: float64(elem.Value)})
-		}
-	}
-	return result
-}
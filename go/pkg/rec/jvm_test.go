@@ -77,3 +77,50 @@ func TestGetMaxAfterFullGC(t *testing.T) {
 		})
 	}
 }
+
+func TestJVMXmxPercent(t *testing.T) {
+	tests := []struct {
+		name    string
+		usage   JVMContainerUsage
+		percent float64
+		source  string
+	}{
+		{"flag", JVMContainerUsage{HeapMaxMB: 1536, HeapMaxSource: jvmXmxSourceFlag, YoungPoolMB: 2048, OldPoolMB: 2048}, 75, jvmXmxSourceFlag},
+		{"heap max on ZGC generational", JVMContainerUsage{HeapMaxMB: 1024, HeapMaxSource: jvmXmxSourceHeapMax, OldPoolMB: 1024}, 50, jvmXmxSourceHeapMax},
+		{"pools", JVMContainerUsage{YoungPoolMB: 512, OldPoolMB: 1024}, 75, jvmXmxSourcePools},
+		{"no Old Gen max", JVMContainerUsage{}, 0, jvmXmxSourcePools},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			percent, source := jvmXmxPercent(tt.usage, 2048)
+			if percent != tt.percent || source != tt.source {
+				t.Errorf("jvmXmxPercent() = %v %s; want %v %s", percent, source, tt.percent, tt.source)
+			}
+		})
+	}
+}
+
+func TestGCPressureMinXmx(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxPercent  float64
+		growPercent float64
+		overhead    float64
+		expected    float64
+	}{
+		{"low overhead", 10, 0, 2, 0},
+		{"high overhead keeps the heap", 10, 0, 15, 1000},
+		{"high overhead grows the heap", 10, 20, 15, 1200},
+		{"guardrail disabled", 0, 20, 15, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Recommender{GCOverheadMaxPercent: tt.maxPercent, GCOverheadGrowPercent: tt.growPercent}
+			if result := r.gcPressureMinXmx(tt.overhead, 1000); result != tt.expected {
+				t.Errorf("gcPressureMinXmx() = %v; want %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package rec
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	//JVM heap flag style
+	jvmFlagStylePercentage = "percentage"
+	jvmFlagStyleXmx        = "xmx"
+)
+
+// applyJVMFlags sets the JVM heap flags matching the final memory limit
+// the heap is the one needed (new Xmx) without exceeding the share of the limit it has today (Xmx%), the rest is left outside of the heap
+// -Xmn keeps the observed young generation size, at most a third of the heap (default NewRatio=2)
+// while the limit is stepped down the heap keeps its current share of the stepped limit, it only reaches the heap needed with the target limit
+// the heap flags are merged into the current JVM options of the container
+func (r *Recommender) applyJVMFlags(c *Recommendation, currentOptions string) {
+	if c.NewMemLimitMB <= 0 || c.NewJVMXmxMB <= 0 {
+		return
+	}
+	if c.StepsLeft > 0 {
+		c.NewJVMXmxMB = math.Ceil(c.NewMemLimitMB * c.JVMXmxPercent / 100.0)
+	} else {
+		c.NewJVMXmxMB = math.Ceil(math.Min(c.NewJVMXmxMB, c.NewMemLimitMB*c.JVMXmxPercent/100.0))
+	}
+	c.NewJVMMaxRAMPercentage = math.Floor(c.NewJVMXmxMB*1000.0/c.NewMemLimitMB) / 10.0
+	c.NewJVMXmnMB = 0
+	if r.JVMRecommendXmn && c.JVMYoungGenMB > 0 {
+		c.NewJVMXmnMB = math.Ceil(math.Min(c.JVMYoungGenMB, c.NewJVMXmxMB/3.0))
+	}
+	c.JVMOptions = mergeJVMOptions(currentOptions, jvmOptions(r.JVMFlagStyle, c.NewJVMXmxMB, c.NewJVMMaxRAMPercentage, c.NewJVMXmnMB))
+	c.NativeHeadroomMB = c.NewMemLimitMB - c.NewJVMXmxMB - c.OffHeapBudgetMB
+}
+
+// jvmOptions returns the JAVA_TOOL_OPTIONS style string of the heap flags
+func jvmOptions(style string, xmxMB, maxRAMPercentage, xmnMB float64) string {
+	options := []string{}
+	if style == jvmFlagStyleXmx {
+		options = append(options, "-Xmx"+strconv.FormatFloat(xmxMB, 'f', 0, 64)+"m")
+	} else {
+		options = append(options, "-XX:MaxRAMPercentage="+strconv.FormatFloat(maxRAMPercentage, 'f', 1, 64))
+	}
+	if xmnMB > 0 {
+		options = append(options, "-Xmn"+strconv.FormatFloat(xmnMB, 'f', 0, 64)+"m")
+	}
+	return strings.Join(options, " ")
+}
+
+// mergeJVMOptions returns the current JVM options with the heap flags replaced, the other flags are kept
+// the max heap flags are replaced whatever their style (-Xmx takes precedence over -XX:MaxRAMPercentage),
+// the young generation flags only if -Xmn is recommended
+func mergeJVMOptions(current, heapFlags string) string {
+	replaced := []string{"-Xmx", "-XX:MaxHeapSize=", "-XX:MaxRAMPercentage="}
+	if strings.Contains(heapFlags, "-Xmn") {
+		replaced = append(replaced, "-Xmn", "-XX:NewSize=", "-XX:MaxNewSize=")
+	}
+	options := []string{}
+	for _, option := range strings.Fields(current) {
+		if !hasAnyPrefix(option, replaced) {
+			options = append(options, option)
+		}
+	}
+	return strings.Join(append(options, heapFlags), " ")
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
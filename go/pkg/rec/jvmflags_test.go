@@ -0,0 +1,99 @@
+package rec
+
+import (
+	"testing"
+)
+
+func TestApplyJVMFlags(t *testing.T) {
+	tests := []struct {
+		name       string
+		style      string
+		xmn        bool
+		current    string
+		input      Recommendation
+		xmxMB      float64
+		percentage float64
+		xmnMB      float64
+		options    string
+	}{
+		{
+			name:       "Heap needed below the current Xmx%",
+			style:      jvmFlagStylePercentage,
+			input:      Recommendation{NewMemLimitMB: 2000, JVMXmxPercent: 75, NewJVMXmxMB: 1200, OffHeapBudgetMB: 300},
+			xmxMB:      1200,
+			percentage: 60,
+			options:    "-XX:MaxRAMPercentage=60.0",
+		},
+		{
+			name:       "Limit capped below the heap needed",
+			style:      jvmFlagStyleXmx,
+			input:      Recommendation{NewMemLimitMB: 1000, JVMXmxPercent: 75, NewJVMXmxMB: 1200},
+			xmxMB:      750,
+			percentage: 75,
+			options:    "-Xmx750m",
+		},
+		{
+			name:       "Young generation capped at a third of the heap",
+			style:      jvmFlagStyleXmx,
+			xmn:        true,
+			input:      Recommendation{NewMemLimitMB: 2000, JVMXmxPercent: 75, NewJVMXmxMB: 900, JVMYoungGenMB: 500},
+			xmxMB:      900,
+			percentage: 45,
+			xmnMB:      300,
+			options:    "-Xmx900m -Xmn300m",
+		},
+		{
+			name:       "Heap flags merged into the current options",
+			style:      jvmFlagStylePercentage,
+			current:    "-Xmx1500m -XX:+UseG1GC -Dfile.encoding=UTF-8",
+			input:      Recommendation{NewMemLimitMB: 2000, JVMXmxPercent: 75, NewJVMXmxMB: 1200},
+			xmxMB:      1200,
+			percentage: 60,
+			options:    "-XX:+UseG1GC -Dfile.encoding=UTF-8 -XX:MaxRAMPercentage=60.0",
+		},
+		{
+			name:       "Stepped down limit keeps the current heap share",
+			style:      jvmFlagStyleXmx,
+			input:      Recommendation{NewMemLimitMB: 3000, JVMXmxPercent: 75, NewJVMXmxMB: 1000, StepsLeft: 3},
+			xmxMB:      2250,
+			percentage: 75,
+			options:    "-Xmx2250m",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Recommender{JVMFlagStyle: tt.style, JVMRecommendXmn: tt.xmn}
+			c := tt.input
+			r.applyJVMFlags(&c, tt.current)
+			if c.NewJVMXmxMB != tt.xmxMB || c.NewJVMMaxRAMPercentage != tt.percentage || c.NewJVMXmnMB != tt.xmnMB || c.JVMOptions != tt.options {
+				t.Errorf("applyJVMFlags() = %v %v %v %q; want %v %v %v %q", c.NewJVMXmxMB, c.NewJVMMaxRAMPercentage, c.NewJVMXmnMB, c.JVMOptions, tt.xmxMB, tt.percentage, tt.xmnMB, tt.options)
+			}
+			if headroom := c.NewMemLimitMB - c.NewJVMXmxMB - c.OffHeapBudgetMB; c.NativeHeadroomMB != headroom {
+				t.Errorf("applyJVMFlags() native headroom = %v; want %v", c.NativeHeadroomMB, headroom)
+			}
+		})
+	}
+}
+
+func TestMergeJVMOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		current   string
+		heapFlags string
+		expected  string
+	}{
+		{"No current options", "", "-Xmx900m", "-Xmx900m"},
+		{"Max heap replaced whatever its style", "-XX:MaxRAMPercentage=75.0 -XX:+ExitOnOutOfMemoryError", "-Xmx900m", "-XX:+ExitOnOutOfMemoryError -Xmx900m"},
+		{"Young generation kept without -Xmn", "-Xmx1g -Xmn400m", "-XX:MaxRAMPercentage=60.0", "-Xmn400m -XX:MaxRAMPercentage=60.0"},
+		{"Young generation replaced with -Xmn", "-Xmx1g -XX:NewSize=400m -XX:MaxNewSize=400m", "-Xmx900m -Xmn300m", "-Xmx900m -Xmn300m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if options := mergeJVMOptions(tt.current, tt.heapFlags); options != tt.expected {
+				t.Errorf("mergeJVMOptions(%q, %q) = %q; want %q", tt.current, tt.heapFlags, options, tt.expected)
+			}
+		})
+	}
+}
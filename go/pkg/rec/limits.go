@@ -1,6 +1,7 @@
 package rec
 
 import (
+	"time"
 	"vpr/pkg/utils"
 
 	"github.com/prometheus/common/model"
@@ -12,6 +13,11 @@ const (
 	queryMemLimit = `max by (container)(kube_pod_container_resource_limits_memory_bytes{namespace=~"$namespace",pod=~"$podgroup$suffix"}) / 1048576`
 	queryCPUReq   = `max by (container)(kube_pod_container_resource_requests_cpu_cores{namespace=~"$namespace",pod=~"$podgroup$suffix"}) * 1000`
 	queryMemReq   = `max by (container)(kube_pod_container_resource_requests_memory_bytes{namespace=~"$namespace",pod=~"$podgroup$suffix"}) / 1048576`
+	//the pods of the CronJobs and Jobs only exist during their runs, max over the data window
+	queryBatchCPULimit = `max by (container)(max_over_time(kube_pod_container_resource_limits_cpu_cores{namespace=~"$namespace",pod=~"$podgroup$suffix"}[$history])) * 1000`
+	queryBatchMemLimit = `max by (container)(max_over_time(kube_pod_container_resource_limits_memory_bytes{namespace=~"$namespace",pod=~"$podgroup$suffix"}[$history])) / 1048576`
+	queryBatchCPUReq   = `max by (container)(max_over_time(kube_pod_container_resource_requests_cpu_cores{namespace=~"$namespace",pod=~"$podgroup$suffix"}[$history])) * 1000`
+	queryBatchMemReq   = `max by (container)(max_over_time(kube_pod_container_resource_requests_memory_bytes{namespace=~"$namespace",pod=~"$podgroup$suffix"}[$history])) / 1048576`
 )
 
 // ContainerLimits is a struct with all containers limits and requests
@@ -28,14 +34,21 @@ type containerValue struct {
 }
 
 // GetPodGroupLimits get Pod groups limits
-func (r *Recommender) GetPodGroupLimits(namespace, podgroup, suffixKind string) map[string]ContainerLimits {
+// the limits of a CronJob or a Job are the max over the data window as its pods may not be running at query time
+func (r *Recommender) GetPodGroupLimits(podGroup PodGroup) map[string]ContainerLimits {
 	result := make(map[string]ContainerLimits)
-	nsVars := []utils.Var{{Name: "namespace", Value: namespace}, {Name: "podgroup", Value: podgroup}, {Name: "suffix", Value: suffixKind}}
+	nsVars := []utils.Var{{Name: "namespace", Value: podGroup.Namespace}, {Name: "podgroup", Value: podGroup.Name}, {Name: "suffix", Value: podGroup.Suffix}}
 
-	cpuReq := r.getContainerValue(queryCPUReq, nsVars)
-	memReq := r.getContainerValue(queryMemReq, nsVars)
-	cpuLimit := r.getContainerValue(queryCPULimit, nsVars)
-	memLimit := r.getContainerValue(queryMemLimit, nsVars)
+	queries := []string{queryCPUReq, queryMemReq, queryCPULimit, queryMemLimit}
+	if isBatch(podGroup.Kind) {
+		window := r.usageWindowFor(podGroup.Namespace, podGroup.Name)
+		nsVars = append(nsVars, utils.Var{Name: "history", Value: model.Duration(time.Since(window.From).Round(time.Minute)).String()})
+		queries = []string{queryBatchCPUReq, queryBatchMemReq, queryBatchCPULimit, queryBatchMemLimit}
+	}
+	cpuReq := r.getContainerValue(queries[0], nsVars)
+	memReq := r.getContainerValue(queries[1], nsVars)
+	cpuLimit := r.getContainerValue(queries[2], nsVars)
+	memLimit := r.getContainerValue(queries[3], nsVars)
 
 	for _, elem := range cpuReq {
 		result[elem.Name] = ContainerLimits{CPUReqM: elem.Value}
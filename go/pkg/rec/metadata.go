@@ -0,0 +1,71 @@
+package rec
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"vpr/pkg/types"
+	"vpr/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// OutPathRunMetadata is the path of the metadata of the last run
+const OutPathRunMetadata = types.DataPath + "run_metadata.yaml"
+
+// RunMetadata describes how the last run was computed
+type RunMetadata struct {
+	Time             time.Time                `yaml:"time"`
+	History          string                   `yaml:"history"`
+	Interval         string                   `yaml:"interval"`
+	Recommendations  int                      `yaml:"recommendations"`
+	ExclusionWindows []appliedExclusionWindow `yaml:"exclusion_windows"`
+	//pod groups whose data window is not the full history
+	DataWindows []podGroupDataWindow `yaml:"data_windows"`
+}
+
+type appliedExclusionWindow struct {
+	utils.ExclusionWindow `yaml:",inline"`
+	DroppedSamples        int `yaml:"dropped_samples"`
+}
+
+type podGroupDataWindow struct {
+	Namespace string    `yaml:"namespace"`
+	PodGroup  string    `yaml:"pod_group"`
+	Window    string    `yaml:"window"`
+	From      time.Time `yaml:"from"`
+	Rollout   time.Time `yaml:"rollout"`
+}
+
+// SaveRunMetadata writes the metadata of the run
+func (r *Recommender) SaveRunMetadata(rec []Recommendation) {
+	metadata := RunMetadata{
+		Time:             time.Now(),
+		History:          r.History.String(),
+		Interval:         r.Interval.String(),
+		Recommendations:  len(rec),
+		ExclusionWindows: r.appliedExclusionWindows(),
+		DataWindows:      []podGroupDataWindow{},
+	}
+	for key, window := range r.dataWindows {
+		if window.Kind == dataWindowHistory {
+			continue
+		}
+		namespacePodGroup := strings.SplitN(key, "/", 2)
+		metadata.DataWindows = append(metadata.DataWindows, podGroupDataWindow{Namespace: namespacePodGroup[0], PodGroup: namespacePodGroup[1], Window: window.Kind, From: window.From, Rollout: window.Rollout})
+	}
+	sort.Slice(metadata.DataWindows, func(i, j int) bool {
+		return metadata.DataWindows[i].Namespace+"/"+metadata.DataWindows[i].PodGroup < metadata.DataWindows[j].Namespace+"/"+metadata.DataWindows[j].PodGroup
+	})
+	yamlData, err := yaml.Marshal(metadata)
+	if err != nil {
+		log.Error("Error marshaling run metadata to YAML: ", err)
+		return
+	}
+	err = os.WriteFile(OutPathRunMetadata, yamlData, 0644)
+	if err != nil {
+		log.Error("Error writing run metadata file ", OutPathRunMetadata, " err ", err)
+	}
+}
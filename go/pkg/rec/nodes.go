@@ -0,0 +1,156 @@
+package rec
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"vpr/pkg/utils"
+
+	"github.com/prometheus/common/model"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	queryNodeAllocatable = `max by (node,resource)(kube_node_status_allocatable{resource=~"cpu|memory"})`
+	queryNodePools       = `max by (node,$poollabel)(kube_node_labels)`
+	//node fit of a pod
+	nodeFitOK            = "ok"
+	nodeFitTooLarge      = "too-large"
+	nodeFitLimitTooLarge = "limit-too-large"
+	nodeFitLargeFraction = "large-fraction"
+)
+
+// NodePool is a group of nodes sharing the node pool label, its allocatable is the smallest of its nodes
+type NodePool struct {
+	Name  string
+	Nodes int
+	CPUM  float64
+	MemMB float64
+}
+
+// kubeStateLabel returns the kube-state-metrics label name of a Kubernetes label (e.g. app.kubernetes.io/name => label_app_kubernetes_io_name)
+func kubeStateLabel(name string) string {
+	return "label_" + regexp.MustCompile(`[^a-zA-Z0-9_]`).ReplaceAllString(name, "_")
+}
+
+// GetNodePools returns the node pools sorted from the smallest to the largest and keeps the node pool of each node
+func (r *Recommender) GetNodePools() []NodePool {
+	type node struct{ cpuM, memMB float64 }
+	nodes := make(map[string]*node)
+	for _, elem := range r.queryVector(queryNodeAllocatable, nil) {
+		name := string(elem.Metric["node"])
+		if _, ok := nodes[name]; !ok {
+			nodes[name] = &node{}
+		}
+		switch string(elem.Metric["resource"]) {
+		case "cpu":
+			nodes[name].cpuM = float64(elem.Value) * 1000
+		case "memory":
+			nodes[name].memMB = float64(elem.Value) / 1048576
+		}
+	}
+	poolLabel := kubeStateLabel(r.NodePoolLabel)
+	poolOf := make(map[string]string)
+	for _, elem := range r.queryVector(queryNodePools, []utils.Var{{Name: "poollabel", Value: poolLabel}}) {
+		poolOf[string(elem.Metric["node"])] = string(elem.Metric[model.LabelName(poolLabel)])
+	}
+
+	pools := make(map[string]*NodePool)
+	r.nodePoolOf = make(map[string]string)
+	for name, n := range nodes {
+		poolName := poolOf[name]
+		if poolName == "" {
+			poolName = "none"
+		}
+		r.nodePoolOf[name] = poolName
+		pool, ok := pools[poolName]
+		if !ok {
+			pool = &NodePool{Name: poolName, CPUM: n.cpuM, MemMB: n.memMB}
+			pools[poolName] = pool
+		}
+		pool.Nodes++
+		pool.CPUM = math.Min(pool.CPUM, n.cpuM)
+		pool.MemMB = math.Min(pool.MemMB, n.memMB)
+	}
+	result := []NodePool{}
+	for _, pool := range pools {
+		result = append(result, *pool)
+	}
+	sortNodePools(result)
+	for _, pool := range result {
+		log.Info("Node pool ", pool.Name, " with ", pool.Nodes, " nodes allocatable CPU ", pool.CPUM, " m Mem ", pool.MemMB, " Mi")
+	}
+	return result
+}
+
+func sortNodePools(pools []NodePool) {
+	sort.Slice(pools, func(i, j int) bool {
+		if pools[i].MemMB != pools[j].MemMB {
+			return pools[i].MemMB < pools[j].MemMB
+		}
+		if pools[i].CPUM != pools[j].CPUM {
+			return pools[i].CPUM < pools[j].CPUM
+		}
+		return pools[i].Name < pools[j].Name
+	})
+}
+
+// CheckNodeFit checks the recommended pods (sum of their containers) against the node pools
+// a pod is too large if its requests fit no node pool, limit-too-large if its memory limit exceeds the nodes fitting its requests
+// and large-fraction if it takes more than NodeFitMaxFraction of the largest node
+func (r *Recommender) CheckNodeFit(recs []Recommendation, pools []NodePool) {
+	if len(pools) == 0 {
+		return
+	}
+	type pod struct{ cpuReqM, memReqMB, memLimitMB float64 }
+	pods := make(map[string]*pod)
+	for _, c := range recs {
+		key := podKey(c)
+		if _, ok := pods[key]; !ok {
+			pods[key] = &pod{}
+		}
+		pods[key].cpuReqM += c.NewCPUReqM
+		pods[key].memReqMB += c.NewMemReqMB
+		pods[key].memLimitMB += c.NewMemLimitMB
+	}
+	largestCPUM, largestMemMB := 0.0, 0.0
+	for _, pool := range pools {
+		largestCPUM = math.Max(largestCPUM, pool.CPUM)
+		largestMemMB = math.Max(largestMemMB, pool.MemMB)
+	}
+
+	for i := range recs {
+		c := &recs[i]
+		p := pods[podKey(*c)]
+		//smallest node pool fitting the requests and the memory limit
+		fitsRequests := false
+		c.SmallestNodePool = ""
+		for _, pool := range pools {
+			if p.cpuReqM <= pool.CPUM && p.memReqMB <= pool.MemMB {
+				fitsRequests = true
+				if p.memLimitMB <= pool.MemMB {
+					c.SmallestNodePool = pool.Name
+					break
+				}
+			}
+		}
+		switch {
+		case !fitsRequests:
+			c.NodeFit = nodeFitTooLarge
+		case c.SmallestNodePool == "":
+			c.NodeFit = nodeFitLimitTooLarge
+		case p.cpuReqM > r.NodeFitMaxFraction*largestCPUM || p.memReqMB > r.NodeFitMaxFraction*largestMemMB:
+			c.NodeFit = nodeFitLargeFraction
+		default:
+			c.NodeFit = nodeFitOK
+		}
+		if c.NodeFit != nodeFitOK {
+			log.Warn("Pod ", c.PodGroupName, " container ", c.ContainerName, " node fit ", c.NodeFit, " (pod CPU req ", p.cpuReqM, " m Mem req ", p.memReqMB, " Mi Mem limit ", p.memLimitMB, " Mi)")
+		}
+	}
+}
+
+// podKey identifies the pod of a recommendation (its containers are summed)
+func podKey(c Recommendation) string {
+	return c.Namespace + "/" + c.Kind + "/" + c.PodGroupName + "/" + c.Role
+}
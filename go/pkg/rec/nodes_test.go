@@ -0,0 +1,35 @@
+package rec
+
+import (
+	"testing"
+)
+
+func TestCheckNodeFit(t *testing.T) {
+	r := &Recommender{NodeFitMaxFraction: 0.5}
+	pools := []NodePool{
+		{Name: "small", CPUM: 1900, MemMB: 6000},
+		{Name: "large", CPUM: 7800, MemMB: 28000},
+	}
+	sortNodePools(pools)
+	recs := []Recommendation{
+		{Namespace: "ns", Kind: dep, PodGroupName: "api", ContainerName: "main", NewCPUReqM: 500, NewMemReqMB: 1024, NewMemLimitMB: 2048},
+		{Namespace: "ns", Kind: dep, PodGroupName: "api", ContainerName: "sidecar", NewCPUReqM: 100, NewMemReqMB: 128, NewMemLimitMB: 256},
+		{Namespace: "ns", Kind: sts, PodGroupName: "elastic", ContainerName: "main", NewCPUReqM: 3000, NewMemReqMB: 16000, NewMemLimitMB: 16000},
+		{Namespace: "ns", Kind: sts, PodGroupName: "jvm", ContainerName: "main", NewCPUReqM: 1000, NewMemReqMB: 20000, NewMemLimitMB: 32000},
+		{Namespace: "ns", Kind: sts, PodGroupName: "huge", ContainerName: "main", NewCPUReqM: 9000, NewMemReqMB: 4000, NewMemLimitMB: 4000},
+	}
+
+	r.CheckNodeFit(recs, pools)
+	expected := []struct{ fit, pool string }{
+		{nodeFitOK, "small"},
+		{nodeFitOK, "small"},
+		{nodeFitLargeFraction, "large"},
+		{nodeFitLimitTooLarge, ""},
+		{nodeFitTooLarge, ""},
+	}
+	for i, e := range expected {
+		if recs[i].NodeFit != e.fit || recs[i].SmallestNodePool != e.pool {
+			t.Errorf("CheckNodeFit() %s/%s = %v %v; want %v %v", recs[i].PodGroupName, recs[i].ContainerName, recs[i].NodeFit, recs[i].SmallestNodePool, e.fit, e.pool)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package rec
+
+// offHeapBudgetMB returns the memory used outside the heap: non-heap pools (metaspace, code cache...), direct/mapped buffers and the thread stacks
+func (r *Recommender) offHeapBudgetMB(usage JVMContainerUsage) float64 {
+	return usage.NonHeapMB + usage.BufferPoolsMB + usage.Threads*r.JVMThreadStackKB/1024.0
+}
+
+// jvmLimitMB returns the limit giving newXmx to the heap and room for the off-heap budget outside of it
+// the heap remains xmxPercent of the limit (e.g. MaxRAMPercentage)
+func jvmLimitMB(newXmx, xmxPercent, offHeapBudget float64) float64 {
+	limit := newXmx * 100.0 / xmxPercent
+	if offHeapBudget <= 0 {
+		return limit
+	}
+	if xmxPercent >= 100.0 {
+		//no room outside of the heap with the current Xmx%
+		return newXmx + offHeapBudget
+	}
+	if offHeapLimit := offHeapBudget * 100.0 / (100.0 - xmxPercent); offHeapLimit > limit {
+		return offHeapLimit
+	}
+	return limit
+}
+
+// nativeHeadroomMB returns the memory left outside the heap and the off-heap budget (negative if the container may be OOMKilled by the kernel)
+func nativeHeadroomMB(limit, xmxPercent, offHeapBudget float64) float64 {
+	return limit*(100.0-xmxPercent)/100.0 - offHeapBudget
+}
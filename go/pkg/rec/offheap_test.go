@@ -0,0 +1,38 @@
+package rec
+
+import (
+	"testing"
+)
+
+func TestJVMLimitMB(t *testing.T) {
+	tests := []struct {
+		name          string
+		newXmx        float64
+		xmxPercent    float64
+		offHeapBudget float64
+		expected      float64
+		headroom      float64
+	}{
+		{"no off-heap metrics", 750, 75, 0, 1000, 250},
+		{"off-heap fits outside of the heap", 750, 75, 200, 1000, 50},
+		{"large direct buffers", 750, 75, 500, 2000, 0},
+		{"Xmx 100%", 1000, 100, 300, 1300, -300},
+	}
+
+	r := &Recommender{JVMThreadStackKB: 1024}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := jvmLimitMB(tt.newXmx, tt.xmxPercent, tt.offHeapBudget)
+			if result != tt.expected {
+				t.Errorf("jvmLimitMB() = %v; want %v", result, tt.expected)
+			}
+			if headroom := nativeHeadroomMB(result, tt.xmxPercent, tt.offHeapBudget); headroom != tt.headroom {
+				t.Errorf("nativeHeadroomMB() = %v; want %v", headroom, tt.headroom)
+			}
+		})
+	}
+
+	if budget := r.offHeapBudgetMB(JVMContainerUsage{NonHeapMB: 150, BufferPoolsMB: 64, Threads: 200}); budget != 414 {
+		t.Errorf("offHeapBudgetMB() = %v; want 414", budget)
+	}
+}
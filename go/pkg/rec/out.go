@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 	"vpr/pkg/types"
 	"vpr/pkg/utils"
 
@@ -35,7 +36,18 @@ func (r *Recommender) GenCSVRecommendations(rec []Recommendation) {
 	csvData := [][]string{{"Namespace", "Kind", "PodGroupName", "Replicas", "ContainerName", "LimitAlias",
 		"CPUReqM", "MemReqMB", "CPULimitM", "MemLimitMB", "NewCPUReqM", "NewMemReqMB", "NewMemLimitMB", "GainCPUReqM", "GainMemReqMB",
 		"CPUMinM", "CPUMeanM", "CPUPercentileM", "CPUMaxM", "MemMinMB", "MemMeanMB", "MemPercentileMB", "MemMaxMB",
-		"JVMYoungGenMB", "JVMYoungGenMinMB", "JVMYoungGenMaxAfterGCMB", "JVMYoungGenMaxMB", "JVMOldGenMinMB", "JVMOldGenMaxAfterFullGCMB", "JVMOldGenMaxMB", "JVMXmxPercent", "JVMAllocationStalls"}}
+		"JVMYoungGenMB", "JVMYoungGenMinMB", "JVMYoungGenMaxAfterGCMB", "JVMYoungGenMaxMB", "JVMOldGenMinMB", "JVMOldGenMaxAfterFullGCMB", "JVMOldGenMaxMB", "JVMXmxPercent", "JVMAllocationStalls",
+		"TargetCPUReqM", "TargetMemReqMB", "TargetMemLimitMB", "StepsLeft", "RiskFix",
+		"QoSClass", "TargetQoSClass", "NewCPULimitM",
+		"CPUProfile", "CPUPeakBucket", "MemProfile", "MemPeakBucket",
+		"MemSlopeMBPerDay", "JVMOldGenSlopeMBPerDay", "LeakSuspected", "DataWindow", "DataSince", "CPUStartupPeakM", "CPUSkew", "MemSkew", "OutlierPods", "Role",
+		"HPA", "AvgReplicas", "HPATargetCPUPercent", "HPASuggestedCPUPercent", "HPATargetMemPercent", "HPASuggestedMemPercent", "NodeFit", "SmallestNodePool",
+		"NodePool", "Team", "MonthlySavings", "JVMDialect",
+		"OffHeapBudgetMB", "NativeHeadroomMB", "NewJVMXmxMB", "NewJVMMaxRAMPercentage", "NewJVMXmnMB", "JVMOptions",
+		"JVMXmxSource", "JVMVersion", "JVMGCOverheadPercent", "JVMGCMaxPauseMs", "JVMSignals",
+		"Runtime", "RuntimeKnob", "SparkApp",
+		"BatchRuns", "BatchFailedRuns", "BatchMeanDurationSec", "BatchMaxDurationSec",
+		"CPURiskFix", "MemRiskFix"}}
 	for _, elem := range rec {
 		csvData = append(csvData, [][]string{{
 			elem.Namespace,
@@ -70,6 +82,60 @@ func (r *Recommender) GenCSVRecommendations(rec []Recommendation) {
 			strconv.FormatFloat(elem.JVMOldGenMaxMB, 'f', 0, 64),
 			strconv.FormatFloat(elem.JVMXmxPercent, 'f', 0, 64),
 			strconv.Itoa(elem.JVMAllocationStalls),
+			strconv.FormatFloat(elem.TargetCPUReqM, 'f', 0, 64),
+			strconv.FormatFloat(elem.TargetMemReqMB, 'f', 0, 64),
+			strconv.FormatFloat(elem.TargetMemLimitMB, 'f', 0, 64),
+			strconv.Itoa(elem.StepsLeft),
+			strconv.FormatBool(elem.RiskFix),
+			elem.QoSClass,
+			elem.TargetQoSClass,
+			strconv.FormatFloat(elem.NewCPULimitM, 'f', 0, 64),
+			elem.CPUProfile,
+			elem.CPUPeakBucket,
+			elem.MemProfile,
+			elem.MemPeakBucket,
+			strconv.FormatFloat(elem.MemSlopeMBPerDay, 'f', 1, 64),
+			strconv.FormatFloat(elem.JVMOldGenSlopeMBPerDay, 'f', 1, 64),
+			strconv.FormatBool(elem.LeakSuspected),
+			elem.DataWindow,
+			elem.DataSince.Format(time.RFC3339),
+			strconv.FormatFloat(elem.CPUStartupPeakM, 'f', 0, 64),
+			strconv.FormatFloat(elem.CPUSkew, 'f', 2, 64),
+			strconv.FormatFloat(elem.MemSkew, 'f', 2, 64),
+			strings.Join(elem.OutlierPods, "|"),
+			elem.Role,
+			elem.HPA,
+			strconv.FormatFloat(elem.AvgReplicas, 'f', 1, 64),
+			strconv.FormatFloat(elem.HPATargetCPUPercent, 'f', 0, 64),
+			strconv.FormatFloat(elem.HPASuggestedCPUPercent, 'f', 0, 64),
+			strconv.FormatFloat(elem.HPATargetMemPercent, 'f', 0, 64),
+			strconv.FormatFloat(elem.HPASuggestedMemPercent, 'f', 0, 64),
+			elem.NodeFit,
+			elem.SmallestNodePool,
+			elem.NodePool,
+			elem.Team,
+			strconv.FormatFloat(elem.MonthlySavings, 'f', 2, 64),
+			elem.JVMDialect,
+			strconv.FormatFloat(elem.OffHeapBudgetMB, 'f', 0, 64),
+			strconv.FormatFloat(elem.NativeHeadroomMB, 'f', 0, 64),
+			strconv.FormatFloat(elem.NewJVMXmxMB, 'f', 0, 64),
+			strconv.FormatFloat(elem.NewJVMMaxRAMPercentage, 'f', 1, 64),
+			strconv.FormatFloat(elem.NewJVMXmnMB, 'f', 0, 64),
+			elem.JVMOptions,
+			elem.JVMXmxSource,
+			elem.JVMVersion,
+			strconv.FormatFloat(elem.JVMGCOverheadPercent, 'f', 1, 64),
+			strconv.FormatFloat(elem.JVMGCMaxPauseMs, 'f', 0, 64),
+			elem.JVMSignals,
+			elem.Runtime,
+			runtimeKnob(elem.RuntimeKnobName, elem.RuntimeKnobValue),
+			elem.SparkApp,
+			strconv.Itoa(elem.BatchRuns),
+			strconv.Itoa(elem.BatchFailedRuns),
+			strconv.FormatFloat(elem.BatchMeanDurationSec, 'f', 0, 64),
+			strconv.FormatFloat(elem.BatchMaxDurationSec, 'f', 0, 64),
+			strconv.FormatBool(r.cpuRiskFix(elem)),
+			strconv.FormatBool(r.memRiskFix(elem)),
 		}}...)
 	}
 
@@ -82,10 +148,10 @@ func (r *Recommender) CalculateMaxOptimization(result []Recommendation) (string,
 	totalGainMemReqMB := 0.0
 	for _, rec := range result {
 		if rec.LimitAlias != "NA" {
-			if rec.GainCPUReqM > 50.0 {
+			if rec.GainCPUReqM > r.MinGainCPUReqM {
 				totalGainCPUReqM += rec.GainCPUReqM
 			}
-			if rec.GainMemReqMB > 100.0 {
+			if rec.GainMemReqMB > r.MinGainMemReqMB {
 				totalGainMemReqMB += rec.GainMemReqMB
 			}
 		}
@@ -93,6 +159,23 @@ func (r *Recommender) CalculateMaxOptimization(result []Recommendation) (string,
 	return strconv.FormatFloat(totalGainCPUReqM, 'f', 0, 64), strconv.FormatFloat(totalGainMemReqMB/1024.0, 'f', 0, 64)
 }
 
+// CalculateUnderProvisioning calculates the total CPU and Memory missing on under-provisioned containers (risk fixes).
+func (r *Recommender) CalculateUnderProvisioning(result []Recommendation) (string, string) {
+	totalRiskCPUReqM := 0.0
+	totalRiskMemReqMB := 0.0
+	for _, rec := range result {
+		if rec.LimitAlias != "NA" {
+			if r.cpuRiskFix(rec) {
+				totalRiskCPUReqM -= rec.GainCPUReqM
+			}
+			if r.memRiskFix(rec) {
+				totalRiskMemReqMB -= rec.GainMemReqMB
+			}
+		}
+	}
+	return strconv.FormatFloat(totalRiskCPUReqM, 'f', 0, 64), strconv.FormatFloat(totalRiskMemReqMB/1024.0, 'f', 0, 64)
+}
+
 // GenYAMLLimitRecommendations func to generate a YAML file with all Recommendations elements as output
 func (r *Recommender) GenYAMLLimitRecommendations(rec []Recommendation) {
 	//reorder the recommendations by LimitAlias
@@ -184,25 +267,52 @@ func removeDuplicates(recs []Recommendation) []Recommendation {
 				newRec.Kind = rec.Kind
 				newRec.ContainerName = rec.ContainerName
 				//we are looking for keeping only the lowest GainCPUReqM and GainMemReqMB to avoid CPU/Mem shortage
+				//the previous unique recommendation is replaced by the merged one
+				previousRec := uniqueRecs[previousIndex]
 				if rec.GainCPUReqM < previousGainCPU {
 					newRec.GainCPUReqM = (rec.CPUReqM - rec.NewCPUReqM) * float64(newRec.Replicas)
 					newRec.CPUReqM = rec.CPUReqM
 					newRec.NewCPUReqM = rec.NewCPUReqM
+					newRec.NewCPULimitM = rec.NewCPULimitM
+					newRec.TargetCPUReqM = rec.TargetCPUReqM
 				} else {
 					newRec.GainCPUReqM = (previousCPUReq - previousNewCPUReq) * float64(newRec.Replicas)
 					newRec.CPUReqM = previousCPUReq
 					newRec.NewCPUReqM = previousNewCPUReq
+					newRec.NewCPULimitM = previousRec.NewCPULimitM
+					newRec.TargetCPUReqM = previousRec.TargetCPUReqM
 				}
 				if rec.GainMemReqMB < previousGainMem {
 					newRec.GainMemReqMB = (rec.MemReqMB - rec.NewMemReqMB) * float64(newRec.Replicas)
 					newRec.MemReqMB = rec.MemReqMB
 					newRec.NewMemReqMB = rec.NewMemReqMB
 					newRec.NewMemLimitMB = rec.NewMemLimitMB
+					newRec.TargetMemReqMB = rec.TargetMemReqMB
+					newRec.TargetMemLimitMB = rec.TargetMemLimitMB
+					newRec.JVMOptions = rec.JVMOptions
+					newRec.RuntimeKnobValue = rec.RuntimeKnobValue
 				} else {
 					newRec.GainMemReqMB = (previousMemReq - previousNewMemReq) * float64(newRec.Replicas)
 					newRec.MemReqMB = previousMemReq
 					newRec.NewMemReqMB = previousNewMemReq
 					newRec.NewMemLimitMB = previousNewMemLimit
+					newRec.TargetMemReqMB = previousRec.TargetMemReqMB
+					newRec.TargetMemLimitMB = previousRec.TargetMemLimitMB
+					newRec.JVMOptions = previousRec.JVMOptions
+					newRec.RuntimeKnobValue = previousRec.RuntimeKnobValue
+				}
+				newRec.RiskFix = rec.RiskFix || previousRec.RiskFix
+				newRec.Team = rec.Team
+				newRec.JVMOptionsKey = rec.JVMOptionsKey
+				newRec.RuntimeOptionsKey = rec.RuntimeOptionsKey
+				newRec.PriceVCPUHour = rec.PriceVCPUHour
+				newRec.PriceGiBHour = rec.PriceGiBHour
+				newRec.QoSClass = rec.QoSClass
+				newRec.TargetQoSClass = rec.TargetQoSClass
+				if rec.StepsLeft > previousRec.StepsLeft {
+					newRec.StepsLeft = rec.StepsLeft
+				} else {
+					newRec.StepsLeft = previousRec.StepsLeft
 				}
 				uniqueRecs = removeIndex(uniqueRecs, previousIndex)
 				uniqueRecs = append(uniqueRecs, newRec)
@@ -259,6 +369,12 @@ func (r *Recommender) genDimValues(rec []Recommendation) string {
 	previousLevel2 := ""
 	gainCPUReq := 0.0
 	gainMemReq := 0.0
+	riskCPUReq := 0.0
+	riskMemReq := 0.0
+	savings := 0.0
+	//values written from the values root (e.g. the JVM options) and the level 0 of the limit aliases
+	rootValues := make(map[string]string)
+	aliasRoots := make(map[string]bool)
 
 	//generate the date string for now shown as YYYY-MM-DD
 	// this is used to show the date when the recommendations were generated
@@ -267,8 +383,8 @@ func (r *Recommender) genDimValues(rec []Recommendation) string {
 	for _, elem := range rec {
 		if elem.Namespace == r.Namespace || r.Namespace == ".*" {
 			//we dont bend down to pick up pennies
-			//at least 50 m or 100 MiB gain and only if LimitAlias is known
-			if (elem.GainCPUReqM > 50.0 || elem.GainMemReqMB > 100.0) && elem.LimitAlias != "NA" {
+			//at least 50 m or 100 MiB gain (or a risk fix or a QoS class change) and only if LimitAlias is known
+			if (elem.GainCPUReqM > r.MinGainCPUReqM || elem.GainMemReqMB > r.MinGainMemReqMB || elem.RiskFix || qosChange(elem)) && elem.LimitAlias != "NA" {
 				limitLevel := strings.Split(elem.LimitAlias, ".")
 				if len(limitLevel) < 2 || len(limitLevel) > 3 {
 					log.Warn("LimitAlias ", elem.LimitAlias, " for ", elem.PodGroupName, " is not valid, skipping recommendation")
@@ -278,61 +394,143 @@ func (r *Recommender) genDimValues(rec []Recommendation) string {
 				if limitLevel[0] != previousLevel0 {
 					sb.WriteString(limitLevel[0] + ":\n")
 					previousLevel0 = limitLevel[0]
+					aliasRoots[limitLevel[0]] = true
 				}
 
+				//Write level 2
+				if limitLevel[1] != previousLevel1 {
+					sb.WriteString(strings.Repeat("  ", 1) + limitLevel[1] + ":\n")
+					previousLevel1 = limitLevel[1]
+				}
 				//Check if level 3 is present
+				cpuGainUnit := " m"
 				if len(limitLevel) == 3 {
-					//Write level 2
-					if limitLevel[1] != previousLevel1 {
-						sb.WriteString(strings.Repeat("  ", 1) + limitLevel[1] + ":\n")
-						previousLevel1 = limitLevel[1]
-					}
 					if limitLevel[2] != previousLevel2 {
 						sb.WriteString(strings.Repeat("  ", 2) + limitLevel[2] + ":\n")
 						previousLevel2 = limitLevel[2]
 					}
-					sb.WriteString(strings.Repeat("  ", len(limitLevel)) + "# " + elem.Namespace + " | " + elem.PodGroupName + " | " + elem.ContainerName + "\n")
-					sb.WriteString(strings.Repeat("  ", len(limitLevel)) + "requests:\n")
-					if elem.GainCPUReqM > 50.0 {
-						sb.WriteString(strings.Repeat("  ", 1+len(limitLevel)) + "cpu: " + strconv.FormatFloat(elem.NewCPUReqM, 'f', 0, 64) + "m")
-						sb.WriteString(" # Gain " + strconv.FormatFloat(elem.GainCPUReqM, 'f', 0, 64) + "m\n")
-						gainCPUReq += elem.GainCPUReqM
-					}
-					if elem.GainMemReqMB > 100.0 {
-						sb.WriteString(strings.Repeat("  ", 1+len(limitLevel)) + "memory: " + strconv.FormatFloat(elem.NewMemReqMB, 'f', 0, 64) + "Mi")
-						sb.WriteString(" # Gain " + strconv.FormatFloat(elem.GainMemReqMB, 'f', 0, 64) + " Mi\n")
-						sb.WriteString(strings.Repeat("  ", len(limitLevel)) + "limits:\n")
-						sb.WriteString(strings.Repeat("  ", 1+len(limitLevel)) + "memory: " + strconv.FormatFloat(elem.NewMemLimitMB, 'f', 0, 64) + "Mi\n")
-						gainMemReq += elem.GainMemReqMB
-					}
-				} else {
-					//Write level 2
-					if limitLevel[1] != previousLevel1 {
-						sb.WriteString(strings.Repeat("  ", 1) + limitLevel[1] + ":\n")
-						previousLevel1 = limitLevel[1]
-					}
-					sb.WriteString(strings.Repeat("  ", len(limitLevel)) + "# " + elem.Namespace + " | " + elem.PodGroupName + " | " + elem.ContainerName + "\n")
-					sb.WriteString(strings.Repeat("  ", len(limitLevel)) + "requests:\n")
-					if elem.GainCPUReqM > 50.0 {
-						sb.WriteString(strings.Repeat("  ", 1+len(limitLevel)) + "cpu: " + strconv.FormatFloat(elem.NewCPUReqM, 'f', 0, 64) + "m")
-						sb.WriteString(" # Gain " + strconv.FormatFloat(elem.GainCPUReqM, 'f', 0, 64) + " m\n")
-						gainCPUReq += elem.GainCPUReqM
-					}
-					if elem.GainMemReqMB > 100.0 {
-						sb.WriteString(strings.Repeat("  ", 1+len(limitLevel)) + "memory: " + strconv.FormatFloat(elem.NewMemReqMB, 'f', 0, 64) + "Mi")
-						sb.WriteString(" # Gain " + strconv.FormatFloat(elem.GainMemReqMB, 'f', 0, 64) + " Mi\n")
-						sb.WriteString(strings.Repeat("  ", len(limitLevel)) + "limits:\n")
-						sb.WriteString(strings.Repeat("  ", 1+len(limitLevel)) + "memory: " + strconv.FormatFloat(elem.NewMemLimitMB, 'f', 0, 64) + "Mi\n")
-						gainMemReq += elem.GainMemReqMB
-					}
+					cpuGainUnit = "m"
 				}
+				gainCPU, gainMem, riskCPU, riskMem := r.genContainerDimValues(&sb, len(limitLevel), cpuGainUnit, elem, rootValues)
+				gainCPUReq += gainCPU
+				gainMemReq += gainMem
+				riskCPUReq += riskCPU
+				riskMemReq += riskMem
+				savings += monthlySavings(gainCPU, gainMem, elem.PriceVCPUHour, elem.PriceGiBHour)
 			}
 		}
 	}
+	writeRootValues(&sb, rootValues, aliasRoots)
 	sb.WriteString("# Overall gain on CPU req " + strconv.FormatFloat(gainCPUReq, 'f', 0, 64) + " m | Mem req " + strconv.FormatFloat(gainMemReq, 'f', 0, 64) + " Mi\n")
+	if riskCPUReq > 0 || riskMemReq > 0 {
+		sb.WriteString("# Overall under-provisioning fixed on CPU req " + strconv.FormatFloat(riskCPUReq, 'f', 0, 64) + " m | Mem req " + strconv.FormatFloat(riskMemReq, 'f', 0, 64) + " Mi\n")
+	}
+	if r.pricing() {
+		sb.WriteString("# Overall monthly savings $" + strconv.FormatFloat(savings, 'f', 2, 64) + "\n")
+	}
 	return sb.String()
 }
 
+// genContainerDimValues writes the requests/limits of a single container at the given indentation level
+// and returns the CPU and Mem gains and the CPU and Mem risk fixes (under-provisioning) which were written
+// the values written from the values root are collected in rootValues
+func (r *Recommender) genContainerDimValues(sb *strings.Builder, level int, cpuGainUnit string, elem Recommendation, rootValues map[string]string) (float64, float64, float64, float64) {
+	gainCPUReq := 0.0
+	gainMemReq := 0.0
+	riskCPUReq := 0.0
+	riskMemReq := 0.0
+	sb.WriteString(strings.Repeat("  ", level) + "# " + elem.Namespace + " | " + elem.PodGroupName + " | " + elem.ContainerName + "\n")
+	if elem.StepsLeft > 0 {
+		sb.WriteString(strings.Repeat("  ", level) + "# Step-down " + strconv.Itoa(elem.StepsLeft) + " steps left to target cpu " + strconv.FormatFloat(elem.TargetCPUReqM, 'f', 0, 64) + "m" +
+			" | memory " + strconv.FormatFloat(elem.TargetMemReqMB, 'f', 0, 64) + "Mi | memory limit " + strconv.FormatFloat(elem.TargetMemLimitMB, 'f', 0, 64) + "Mi\n")
+	}
+	sb.WriteString(strings.Repeat("  ", level) + "requests:\n")
+	writeCPU := false
+	if elem.GainCPUReqM > r.MinGainCPUReqM {
+		sb.WriteString(strings.Repeat("  ", 1+level) + "cpu: " + strconv.FormatFloat(elem.NewCPUReqM, 'f', 0, 64) + "m")
+		sb.WriteString(" # Gain " + strconv.FormatFloat(elem.GainCPUReqM, 'f', 0, 64) + cpuGainUnit + "\n")
+		gainCPUReq += elem.GainCPUReqM
+		writeCPU = true
+	} else if r.cpuRiskFix(elem) {
+		sb.WriteString(strings.Repeat("  ", 1+level) + "cpu: " + strconv.FormatFloat(elem.NewCPUReqM, 'f', 0, 64) + "m")
+		sb.WriteString(" # Risk fix +" + strconv.FormatFloat(-elem.GainCPUReqM, 'f', 0, 64) + cpuGainUnit + "\n")
+		riskCPUReq -= elem.GainCPUReqM
+		writeCPU = true
+	} else if qosChange(elem) {
+		sb.WriteString(strings.Repeat("  ", 1+level) + "cpu: " + strconv.FormatFloat(elem.NewCPUReqM, 'f', 0, 64) + "m # QoS " + elem.TargetQoSClass + "\n")
+		writeCPU = true
+	}
+	writeMem := false
+	if elem.GainMemReqMB > r.MinGainMemReqMB {
+		sb.WriteString(strings.Repeat("  ", 1+level) + "memory: " + strconv.FormatFloat(elem.NewMemReqMB, 'f', 0, 64) + "Mi")
+		sb.WriteString(" # Gain " + strconv.FormatFloat(elem.GainMemReqMB, 'f', 0, 64) + " Mi\n")
+		gainMemReq += elem.GainMemReqMB
+		writeMem = true
+	} else if r.memRiskFix(elem) {
+		sb.WriteString(strings.Repeat("  ", 1+level) + "memory: " + strconv.FormatFloat(elem.NewMemReqMB, 'f', 0, 64) + "Mi")
+		sb.WriteString(" # Risk fix +" + strconv.FormatFloat(-elem.GainMemReqMB, 'f', 0, 64) + " Mi\n")
+		riskMemReq -= elem.GainMemReqMB
+		writeMem = true
+	} else if qosChange(elem) {
+		sb.WriteString(strings.Repeat("  ", 1+level) + "memory: " + strconv.FormatFloat(elem.NewMemReqMB, 'f', 0, 64) + "Mi # QoS " + elem.TargetQoSClass + "\n")
+		writeMem = true
+	}
+	//a CPU limit is only recommended to keep the pod Guaranteed or to stay above the new request
+	writeCPULimit := writeCPU && elem.NewCPULimitM > 0
+	if writeCPULimit || writeMem {
+		sb.WriteString(strings.Repeat("  ", level) + "limits:\n")
+	}
+	if writeCPULimit {
+		sb.WriteString(strings.Repeat("  ", 1+level) + "cpu: " + strconv.FormatFloat(elem.NewCPULimitM, 'f', 0, 64) + "m\n")
+	}
+	if writeMem {
+		sb.WriteString(strings.Repeat("  ", 1+level) + "memory: " + strconv.FormatFloat(elem.NewMemLimitMB, 'f', 0, 64) + "Mi\n")
+	}
+	//the JVM heap and the runtime memory knob change together with the memory limit
+	if writeMem {
+		if elem.JVMOptionsKey != "" && elem.JVMOptions != "" {
+			rootValues[elem.JVMOptionsKey] = elem.JVMOptions
+		}
+		if elem.RuntimeOptionsKey != "" && elem.RuntimeKnobValue != "" {
+			rootValues[elem.RuntimeOptionsKey] = elem.RuntimeKnobValue
+		}
+	}
+	return gainCPUReq, gainMemReq, riskCPUReq, riskMemReq
+}
+
+// qosChange returns true if the recommendation changes the QoS class: requests and limits are then all written whatever the gains
+func qosChange(elem Recommendation) bool {
+	return elem.TargetQoSClass != "" && elem.TargetQoSClass != elem.QoSClass
+}
+
+// writeRootValues writes the quoted values under their dot-separated key paths from the values root (sorted by key path)
+// a key path under the level 0 of a limit alias would duplicate it and is skipped
+func writeRootValues(sb *strings.Builder, values map[string]string, aliasRoots map[string]bool) {
+	keyPaths := make([]string, 0, len(values))
+	for keyPath := range values {
+		keyPaths = append(keyPaths, keyPath)
+	}
+	sort.Strings(keyPaths)
+	previous := []string{}
+	for _, keyPath := range keyPaths {
+		keys := strings.Split(keyPath, ".")
+		if len(keys) < 2 || aliasRoots[keys[0]] {
+			log.Warn("Helm values key path ", keyPath, " is not valid or under a limit alias, skipping ", values[keyPath])
+			continue
+		}
+		//the levels shared with the previous key path are already written
+		common := 0
+		for common < len(keys)-1 && common < len(previous)-1 && keys[common] == previous[common] {
+			common++
+		}
+		for i := common; i < len(keys)-1; i++ {
+			sb.WriteString(strings.Repeat("  ", i) + keys[i] + ":\n")
+		}
+		sb.WriteString(strings.Repeat("  ", len(keys)-1) + keys[len(keys)-1] + ": \"" + values[keyPath] + "\"\n")
+		previous = keys
+	}
+}
+
 func replaceDashByUnderscore(s string) string {
 	return strings.ReplaceAll(s, "-", "_")
 }
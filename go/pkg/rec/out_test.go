@@ -75,6 +75,227 @@ res:
       limits:
         memory: 512Mi
 # Overall gain on CPU req 51 m | Mem req 101 Mi
+`,
+		},
+		{
+			name: "Step-down Recommendation shows the target",
+			input: []Recommendation{
+				{
+					Namespace:        "tmp",
+					PodGroupName:     "pod-group-1",
+					ContainerName:    "container-1",
+					NewCPUReqM:       500,
+					NewMemReqMB:      2048,
+					NewMemLimitMB:    2048,
+					GainCPUReqM:      500,
+					GainMemReqMB:     2048,
+					TargetCPUReqM:    100,
+					TargetMemReqMB:   300,
+					TargetMemLimitMB: 400,
+					StepsLeft:        4,
+					LimitAlias:       "res.pod_group_1",
+				},
+			},
+			expected: `# VPR recommendations
+res:
+  pod_group_1:
+    # tmp | pod-group-1 | container-1
+    # Step-down 4 steps left to target cpu 100m | memory 300Mi | memory limit 400Mi
+    requests:
+      cpu: 500m # Gain 500 m
+      memory: 2048Mi # Gain 2048 Mi
+    limits:
+      memory: 2048Mi
+# Overall gain on CPU req 500 m | Mem req 2048 Mi
+`,
+		},
+		{
+			name: "Under-provisioned Recommendation is a risk fix",
+			input: []Recommendation{
+				{
+					Namespace:     "tmp",
+					PodGroupName:  "pod-group-1",
+					ContainerName: "container-1",
+					CPUReqM:       500,
+					NewCPUReqM:    800,
+					NewMemReqMB:   1024,
+					NewMemLimitMB: 1200,
+					GainCPUReqM:   -300,
+					GainMemReqMB:  20,
+					RiskFix:       true,
+					LimitAlias:    "res.pod_group_1.container_1",
+				},
+			},
+			expected: `# VPR recommendations
+res:
+  pod_group_1:
+    container_1:
+      # tmp | pod-group-1 | container-1
+      requests:
+        cpu: 800m # Risk fix +300m
+# Overall gain on CPU req 0 m | Mem req 0 Mi
+# Overall under-provisioning fixed on CPU req 300 m | Mem req 0 Mi
+`,
+		},
+		{
+			name: "Risk fix above the current CPU limit raises the CPU limit",
+			input: []Recommendation{
+				{
+					Namespace:     "tmp",
+					PodGroupName:  "worker",
+					ContainerName: "worker",
+					CPUReqM:       500,
+					CPULimitM:     600,
+					NewCPUReqM:    800,
+					NewCPULimitM:  800,
+					GainCPUReqM:   -300,
+					RiskFix:       true,
+					LimitAlias:    "res.worker",
+				},
+				{
+					Namespace:     "tmp",
+					PodGroupName:  "no-request",
+					ContainerName: "no-request",
+					NewCPUReqM:    800,
+					GainCPUReqM:   -800,
+					LimitAlias:    "res.no_request",
+				},
+			},
+			expected: `# VPR recommendations
+res:
+  worker:
+    # tmp | worker | worker
+    requests:
+      cpu: 800m # Risk fix +300 m
+    limits:
+      cpu: 800m
+# Overall gain on CPU req 0 m | Mem req 0 Mi
+# Overall under-provisioning fixed on CPU req 300 m | Mem req 0 Mi
+`,
+		},
+		{
+			name: "Burstable forced to Guaranteed writes requests and limits without gains",
+			input: []Recommendation{
+				{
+					Namespace:      "tmp",
+					PodGroupName:   "db",
+					ContainerName:  "db",
+					CPUReqM:        1000,
+					MemReqMB:       2000,
+					NewCPUReqM:     1010,
+					NewCPULimitM:   1010,
+					NewMemReqMB:    2048,
+					NewMemLimitMB:  2048,
+					GainCPUReqM:    -10,
+					GainMemReqMB:   -48,
+					QoSClass:       qosBurstable,
+					TargetQoSClass: qosGuaranteed,
+					LimitAlias:     "res.db",
+				},
+			},
+			expected: `# VPR recommendations
+res:
+  db:
+    # tmp | db | db
+    requests:
+      cpu: 1010m # QoS Guaranteed
+      memory: 2048Mi # QoS Guaranteed
+    limits:
+      cpu: 1010m
+      memory: 2048Mi
+# Overall gain on CPU req 0 m | Mem req 0 Mi
+`,
+		},
+		{
+			name: "Guaranteed Recommendation has a CPU limit",
+			input: []Recommendation{
+				{
+					Namespace:      "tmp",
+					PodGroupName:   "kafka",
+					ContainerName:  "kafka",
+					NewCPUReqM:     1500,
+					NewCPULimitM:   1500,
+					NewMemReqMB:    3500,
+					NewMemLimitMB:  3500,
+					GainCPUReqM:    500,
+					GainMemReqMB:   596,
+					TargetQoSClass: qosGuaranteed,
+					LimitAlias:     "res.kafka",
+				},
+			},
+			expected: `# VPR recommendations
+res:
+  kafka:
+    # tmp | kafka | kafka
+    requests:
+      cpu: 1500m # Gain 500 m
+      memory: 3500Mi # Gain 596 Mi
+    limits:
+      cpu: 1500m
+      memory: 3500Mi
+# Overall gain on CPU req 500 m | Mem req 596 Mi
+`,
+		},
+		{
+			name: "JVM Recommendation writes the JVM options with the limit",
+			input: []Recommendation{
+				{
+					Namespace:     "tmp",
+					PodGroupName:  "api",
+					ContainerName: "api",
+					NewCPUReqM:    500,
+					NewMemReqMB:   1700,
+					NewMemLimitMB: 2000,
+					GainMemReqMB:  300,
+					JVMOptions:    "-XX:MaxRAMPercentage=60.0",
+					JVMOptionsKey: "api.env.JAVA_TOOL_OPTIONS",
+					LimitAlias:    "res.api",
+				},
+			},
+			expected: `# VPR recommendations
+res:
+  api:
+    # tmp | api | api
+    requests:
+      memory: 1700Mi # Gain 300 Mi
+    limits:
+      memory: 2000Mi
+api:
+  env:
+    JAVA_TOOL_OPTIONS: "-XX:MaxRAMPercentage=60.0"
+# Overall gain on CPU req 0 m | Mem req 300 Mi
+`,
+		},
+		{
+			name: "Go Recommendation writes GOMEMLIMIT with the limit",
+			input: []Recommendation{
+				{
+					Namespace:         "tmp",
+					PodGroupName:      "gateway",
+					ContainerName:     "gateway",
+					NewCPUReqM:        200,
+					NewMemReqMB:       850,
+					NewMemLimitMB:     1000,
+					GainMemReqMB:      150,
+					Runtime:           runtimeGo,
+					RuntimeKnobName:   runtimeKnobGoMemLimit,
+					RuntimeKnobValue:  "900MiB",
+					RuntimeOptionsKey: "gateway.env.GOMEMLIMIT",
+					LimitAlias:        "res.gateway",
+				},
+			},
+			expected: `# VPR recommendations
+res:
+  gateway:
+    # tmp | gateway | gateway
+    requests:
+      memory: 850Mi # Gain 150 Mi
+    limits:
+      memory: 1000Mi
+gateway:
+  env:
+    GOMEMLIMIT: "900MiB"
+# Overall gain on CPU req 0 m | Mem req 150 Mi
 `,
 		},
 		{
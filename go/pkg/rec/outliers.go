@@ -0,0 +1,49 @@
+package rec
+
+import (
+	"sort"
+)
+
+// podSkew returns the ratio between the highest and the median pod percentile
+// and the pods above factor times the median (outliers), at least 2 pods are needed
+func podSkew(pods map[string]float64, factor float64) (float64, []string) {
+	outliers := []string{}
+	if len(pods) < 2 {
+		return 0, outliers
+	}
+	values := make([]float64, 0, len(pods))
+	max := 0.0
+	for _, value := range pods {
+		values = append(values, value)
+		if value > max {
+			max = value
+		}
+	}
+	podMedian := median(values)
+	if podMedian <= 0 {
+		return 0, outliers
+	}
+	for pod, value := range pods {
+		if factor > 0 && value > factor*podMedian {
+			outliers = append(outliers, pod)
+		}
+	}
+	sort.Strings(outliers)
+	return max / podMedian, outliers
+}
+
+// mergeOutliers returns the sorted union of the outlier pods
+func mergeOutliers(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, list := range lists {
+		for _, pod := range list {
+			if !seen[pod] {
+				seen[pod] = true
+				result = append(result, pod)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,75 @@
+package rec
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestPodSkew(t *testing.T) {
+	tests := []struct {
+		name             string
+		pods             map[string]float64
+		expectedSkew     float64
+		expectedOutliers []string
+	}{
+		{"Single pod", map[string]float64{"kafka-0": 800}, 0, []string{}},
+		{"Balanced pods", map[string]float64{"kafka-0": 800, "kafka-1": 1000, "kafka-2": 1200}, 1.2, []string{}},
+		{"Hot partition leader", map[string]float64{"kafka-0": 500, "kafka-1": 2500, "kafka-2": 400}, 5, []string{"kafka-1"}},
+		{"Idle pods", map[string]float64{"kafka-0": 0, "kafka-1": 0}, 0, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skew, outliers := podSkew(tt.pods, 2)
+			if skew != tt.expectedSkew || !reflect.DeepEqual(outliers, tt.expectedOutliers) {
+				t.Errorf("podSkew() = %v, %v; want %v, %v", skew, outliers, tt.expectedSkew, tt.expectedOutliers)
+			}
+		})
+	}
+}
+
+func TestMergeMax(t *testing.T) {
+	start := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	pod0 := genSeries(start, 0, 10, 100)
+	pod1 := genSeries(start, 5, 15, 300)
+
+	result := mergeMax([][]model.SamplePair{pod0, pod1})
+	if len(result) != 15 {
+		t.Fatalf("mergeMax() returned %v samples; want 15", len(result))
+	}
+	for i, sample := range result {
+		expected := model.SampleValue(100)
+		if i >= 5 {
+			expected = 300
+		}
+		if sample.Value != expected || !sample.Timestamp.Time().Equal(start.Add(time.Duration(i)*time.Minute)) {
+			t.Errorf("mergeMax()[%v] = %v; want %v at %v", i, sample, expected, start.Add(time.Duration(i)*time.Minute))
+		}
+	}
+}
+
+func TestSplitStatefulSets(t *testing.T) {
+	r := &Recommender{StsPerOrdinal: true}
+	podGroups := []PodGroup{
+		{Kind: sts, Name: "kafka", Namespace: "data", Suffix: "-\\\\d+", Count: 3},
+		{Kind: dep, Name: "api", Namespace: "data", Suffix: "-\\\\w+-\\\\w+", Count: 2},
+	}
+
+	result := r.splitStatefulSets(podGroups)
+	if len(result) != 4 {
+		t.Fatalf("splitStatefulSets() returned %v pod groups; want 4", len(result))
+	}
+	for i, expected := range []PodGroup{
+		{Kind: sts, Name: "kafka", Namespace: "data", Suffix: "-0", Count: 1, Role: "0"},
+		{Kind: sts, Name: "kafka", Namespace: "data", Suffix: "-1", Count: 1, Role: "1"},
+		{Kind: sts, Name: "kafka", Namespace: "data", Suffix: "-2", Count: 1, Role: "2"},
+		podGroups[1],
+	} {
+		if result[i] != expected {
+			t.Errorf("splitStatefulSets()[%v] = %v; want %v", i, result[i], expected)
+		}
+	}
+}
@@ -13,14 +13,17 @@ const (
 	ds             = "daemonset"
 	dep            = "deployment"
 	cron           = "cronjob"
+	batchJob       = "job"
 	sparkDrivers   = "spark_driver"
 	sparkExecutors = "spark_executor"
 	//all kinds which have at least one pod
 	querySts = `max by(statefulset,namespace)(kube_statefulset_status_replicas_ready{namespace=~"$namespace"}) > 0`
 	// queryRs  = `max by(replicaset,namespace)(kube_replicaset_status_replicas{namespace=~"$namespace"}) > 0`
-	queryDs       = `max by(daemonset,namespace)(kube_daemonset_status_number_ready{namespace=~"$namespace"}) > 0`
-	queryDep      = `max by(deployment,namespace)(kube_deployment_status_replicas_ready{namespace=~"$namespace"}) > 0`
-	queryCron     = `max by(cronjob,namespace)(kube_cronjob_status_active{namespace=~"$namespace"})`
+	queryDs  = `max by(daemonset,namespace)(kube_daemonset_status_number_ready{namespace=~"$namespace"}) > 0`
+	queryDep = `max by(deployment,namespace)(kube_deployment_status_replicas_ready{namespace=~"$namespace"}) > 0`
+	//cron jobs and jobs (without owner) which ran at any time in the history
+	queryCron     = `max by(cronjob,namespace)(label_replace(max_over_time(kube_job_owner{namespace=~"$namespace",owner_kind="CronJob"}[$history]), "cronjob", "$1", "owner_name", "(.*)"))`
+	queryJob      = `max by(job,namespace)(label_replace(max_over_time(kube_job_owner{namespace=~"$namespace",owner_kind="<none>"}[$history]), "job", "$1", "job_name", "(.*)"))`
 	queryDriver   = `max by (spark_driver,namespace)(label_replace(kube_pod_container_info{namespace=~"$namespace",container=~"spark-kubernetes-driver"}, "spark_driver", "$1", "pod", "(.*)"))`
 	queryExecutor = `max by (spark_executor,namespace)(label_replace(kube_pod_container_info{namespace=~"$namespace",container=~"spark-kubernetes-executor"}, "spark_executor", "$1", "pod", "(.*)-\\w+-exec-\\d+"))`
 )
@@ -32,17 +35,20 @@ type PodGroup struct {
 	Namespace string
 	Suffix    string
 	Count     int
+	//role or ordinal of a StatefulSet split per role/ordinal
+	Role string
 }
 
 // GetPodGroups get Pod groups sts/dep/ds/rs
 func (r *Recommender) GetPodGroups() []PodGroup {
 	result := []PodGroup{}
-	nsVars := []utils.Var{{Name: "namespace", Value: r.Namespace}}
-	//get Pod groups sts/dep/daemonset/cronjobs/spark jobs
+	nsVars := []utils.Var{{Name: "namespace", Value: r.Namespace}, {Name: "history", Value: model.Duration(r.History).String()}}
+	//get Pod groups sts/dep/daemonset/cronjobs/jobs/spark jobs
 	result = append(result, r.getPodGroupKind(cron, queryCron, nsVars)...)
+	result = append(result, r.getPodGroupKind(batchJob, queryJob, nsVars)...)
 	result = append(result, r.getPodGroupKind(sparkDrivers, queryDriver, nsVars)...)
 	result = append(result, r.getPodGroupKind(sparkExecutors, queryExecutor, nsVars)...)
-	result = append(result, r.getPodGroupKind(sts, querySts, nsVars)...)
+	result = append(result, r.splitStatefulSets(r.getPodGroupKind(sts, querySts, nsVars))...)
 	// result = append(result, r.getPodGroupKind(rs, queryRs, nsVars)...)
 	result = append(result, r.getPodGroupKind(ds, queryDs, nsVars)...)
 	result = append(result, r.getPodGroupKind(dep, queryDep, nsVars)...)
@@ -87,6 +93,8 @@ func (r *Recommender) getPodGroupSuffix(kind string) string {
 		return "-\\\\w+-\\\\w+"
 	case cron:
 		return "-\\\\w+-\\\\w+"
+	case batchJob:
+		return "-\\\\w+"
 	case sparkDrivers:
 		return ".*"
 	case sparkExecutors:
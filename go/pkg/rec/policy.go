@@ -0,0 +1,134 @@
+package rec
+
+import (
+	"regexp"
+	"vpr/pkg/utils"
+)
+
+// Policy is the sizing policy of a container: the global settings of the Recommender overridden by its limit alias entry
+type Policy struct {
+	LimitAlias           string
+	HelmValueFileName    string
+	UntouchMemoryLimit   bool
+	ExtraMemoryMargin    int
+	CPUPercentile        float64
+	MemPercentile        float64
+	MemLimitToReqPercent float64
+	PodMinCPUMillicores  float64
+	PodMinMemoryMb       float64
+	JVMMinLimitMB        float64
+	JVMFloorLimitMB      float64
+	//below this limit the JVM gets JVMMinLimitMB, JVMFloorLimitMB above
+	JVMFloorThresholdMB float64
+	QoSClass            string
+	//hard bounds (0 means no bound)
+	MinCPUReqM    float64
+	MaxCPUReqM    float64
+	MinMemReqMB   float64
+	MaxMemReqMB   float64
+	MinMemLimitMB float64
+	MaxMemLimitMB float64
+	//team owning the container (cost reporting)
+	Team string
+	//helm values key path of the recommended JVM options and their current value
+	JVMOptionsKey string
+	JVMOptions    string
+	//helm values key path of the recommended runtime memory knob and its current value
+	RuntimeOptionsKey string
+	RuntimeOptions    string
+}
+
+// findPolicy returns the policy of a container, the global one if no limit alias entry matches
+func (r *Recommender) findPolicy(podGroup PodGroup, containerName string) Policy {
+	policy := Policy{
+		LimitAlias:           "NA",
+		CPUPercentile:        r.TargetCPUPercentile,
+		MemPercentile:        r.TargetMemPercentile,
+		MemLimitToReqPercent: r.TargetMemLimitToReqPercent,
+		PodMinCPUMillicores:  r.PodMinCPUMillicores,
+		PodMinMemoryMb:       r.PodMinMemoryMb,
+		JVMMinLimitMB:        r.JVMMinLimitMB,
+		JVMFloorLimitMB:      r.JVMFloorLimitMB,
+		JVMFloorThresholdMB:  r.JVMFloorThresholdMB,
+	}
+	extra, helmValueFileName, found := r.matchExtraParams(podGroup, containerName)
+	if !found {
+		return policy
+	}
+	policy.LimitAlias = extra.LimitAlias
+	policy.HelmValueFileName = helmValueFileName
+	policy.UntouchMemoryLimit = extra.UntouchMemoryLimit
+	policy.ExtraMemoryMargin = extra.ExtraMemoryMargin
+	policy.CPUPercentile = override(policy.CPUPercentile, extra.CPUPercentile)
+	policy.MemPercentile = override(policy.MemPercentile, extra.MemPercentile)
+	policy.MemLimitToReqPercent = override(policy.MemLimitToReqPercent, extra.MemLimitToReqPercent)
+	policy.PodMinCPUMillicores = override(policy.PodMinCPUMillicores, extra.PodMinCPUMillicores)
+	policy.PodMinMemoryMb = override(policy.PodMinMemoryMb, extra.PodMinMemoryMb)
+	policy.JVMMinLimitMB = override(policy.JVMMinLimitMB, extra.JVMMinLimitMB)
+	policy.JVMFloorLimitMB = override(policy.JVMFloorLimitMB, extra.JVMFloorLimitMB)
+	policy.JVMFloorThresholdMB = override(policy.JVMFloorThresholdMB, extra.JVMFloorThresholdMB)
+	policy.QoSClass = extra.QoSClass
+	policy.Team = extra.Team
+	policy.JVMOptionsKey = extra.JVMOptionsKey
+	policy.JVMOptions = extra.JVMOptions
+	policy.RuntimeOptionsKey = extra.RuntimeOptionsKey
+	policy.RuntimeOptions = extra.RuntimeOptions
+	policy.MinCPUReqM = extra.MinCPUReqM
+	policy.MaxCPUReqM = extra.MaxCPUReqM
+	policy.MinMemReqMB = extra.MinMemReqMB
+	policy.MaxMemReqMB = extra.MaxMemReqMB
+	policy.MinMemLimitMB = extra.MinMemLimitMB
+	policy.MaxMemLimitMB = extra.MaxMemLimitMB
+	return policy
+}
+
+// matchExtraParams returns the first limit alias entry matching the pod group and container
+// along with its definitive helm value file name
+func (r *Recommender) matchExtraParams(podGroup PodGroup, containerName string) (utils.PodContainerExtraParams, string, bool) {
+	for _, extra := range r.ExtraParams {
+		//if the Pod name respect the pod name regex
+		//^ is the start of the string, $ is the end of the string
+		matchPodGroup, _ := regexp.MatchString("^"+extra.Pod+"$", podGroup.Name)
+		matchContainer, _ := regexp.MatchString("^"+extra.Container+"$", containerName)
+		if matchPodGroup && matchContainer {
+			definitiveHelmValueFileName := extra.HelmValueFileName
+			//check if the HelmValueFileName contains a $1 pattern to be replaced by the PodGroup regex capture group
+			definitiveHelmValueFileName = replaceCaptureGroup(extra.Pod, podGroup.Name, definitiveHelmValueFileName)
+			definitiveHelmValueFileName = "helm-values-" + definitiveHelmValueFileName
+			return extra, definitiveHelmValueFileName, true
+		}
+	}
+	return utils.PodContainerExtraParams{}, "", false
+}
+
+// applyBounds clamps the recommendation within the hard bounds of the policy
+func (p Policy) applyBounds(c *Recommendation) {
+	c.NewCPUReqM = clamp(c.NewCPUReqM, p.MinCPUReqM, p.MaxCPUReqM)
+	c.NewMemReqMB = clamp(c.NewMemReqMB, p.MinMemReqMB, p.MaxMemReqMB)
+	if !p.UntouchMemoryLimit {
+		c.NewMemLimitMB = clamp(c.NewMemLimitMB, p.MinMemLimitMB, p.MaxMemLimitMB)
+	}
+	//the request bounds cannot make the request go over the limit
+	if c.NewMemLimitMB > 0 && c.NewMemReqMB > c.NewMemLimitMB {
+		c.NewMemReqMB = c.NewMemLimitMB
+	}
+}
+
+// override returns value if it is set (> 0) otherwise the default
+func override(defaultValue, value float64) float64 {
+	if value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// clamp bounds value within [min, max], a bound <= 0 is ignored
+func clamp(value, min, max float64) float64 {
+	if min > 0 && value < min {
+		value = min
+	}
+	if max > 0 && value > max {
+		value = max
+	}
+	return value
+}
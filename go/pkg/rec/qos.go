@@ -0,0 +1,57 @@
+package rec
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	qosGuaranteed = "Guaranteed"
+	qosBurstable  = "Burstable"
+	qosBestEffort = "BestEffort"
+	//QoS policies
+	qosPreserve = "preserve"
+	qosIgnore   = "ignore"
+)
+
+// qosClass returns the QoS class of a container from its current requests and limits
+func qosClass(l ContainerLimits) string {
+	if l.CPUReqM == 0 && l.MemReqMB == 0 && l.CPULimitM == 0 && l.MemLimitMB == 0 {
+		return qosBestEffort
+	}
+	if l.CPULimitM > 0 && l.MemLimitMB > 0 && l.CPUReqM == l.CPULimitM && l.MemReqMB == l.MemLimitMB {
+		return qosGuaranteed
+	}
+	return qosBurstable
+}
+
+// targetQoSClass returns the QoS class to recommend: the one forced by the policy,
+// the current one if it is Guaranteed and the QoS policy is to preserve it, Burstable otherwise
+func (r *Recommender) targetQoSClass(policy Policy, current string) string {
+	switch policy.QoSClass {
+	case qosGuaranteed, qosBurstable:
+		return policy.QoSClass
+	case "":
+	default:
+		log.Warn("Unknown QoS class ", policy.QoSClass, " for limit alias ", policy.LimitAlias, " will be ignored")
+	}
+	if r.QoSPolicy == qosPreserve && current == qosGuaranteed {
+		return qosGuaranteed
+	}
+	return qosBurstable
+}
+
+// applyQoS makes the recommendation comply with the target QoS class
+// Guaranteed means requests == limits for CPU and Mem (the memory limit being the reference)
+// Burstable means no CPU limit is recommended, unless the current one is below the new request (rejected by the API server)
+func applyQoS(c *Recommendation) {
+	if c.TargetQoSClass != qosGuaranteed {
+		c.NewCPULimitM = 0
+		if c.CPULimitM > 0 && c.NewCPUReqM > c.CPULimitM {
+			c.NewCPULimitM = c.NewCPUReqM
+		}
+		return
+	}
+	c.NewCPULimitM = c.NewCPUReqM
+	c.NewMemReqMB = c.NewMemLimitMB
+	c.TargetMemReqMB = c.TargetMemLimitMB
+}
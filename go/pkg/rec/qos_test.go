@@ -0,0 +1,64 @@
+package rec
+
+import (
+	"testing"
+)
+
+func TestQoSClass(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   ContainerLimits
+		expected string
+	}{
+		{"No requests nor limits", ContainerLimits{}, qosBestEffort},
+		{"Requests equal limits", ContainerLimits{CPUReqM: 2000, CPULimitM: 2000, MemReqMB: 4096, MemLimitMB: 4096}, qosGuaranteed},
+		{"No CPU limit", ContainerLimits{CPUReqM: 2000, MemReqMB: 4096, MemLimitMB: 4096}, qosBurstable},
+		{"Mem request lower than limit", ContainerLimits{CPUReqM: 2000, CPULimitM: 2000, MemReqMB: 2048, MemLimitMB: 4096}, qosBurstable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := qosClass(tt.limits); got != tt.expected {
+				t.Errorf("qosClass(%+v) = %v; want %v", tt.limits, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTargetQoSClass(t *testing.T) {
+	preserve := &Recommender{QoSPolicy: qosPreserve}
+	ignore := &Recommender{QoSPolicy: qosIgnore}
+
+	if got := preserve.targetQoSClass(Policy{}, qosGuaranteed); got != qosGuaranteed {
+		t.Errorf("targetQoSClass() preserve = %v; want %v", got, qosGuaranteed)
+	}
+	if got := ignore.targetQoSClass(Policy{}, qosGuaranteed); got != qosBurstable {
+		t.Errorf("targetQoSClass() ignore = %v; want %v", got, qosBurstable)
+	}
+	if got := ignore.targetQoSClass(Policy{QoSClass: qosGuaranteed}, qosBurstable); got != qosGuaranteed {
+		t.Errorf("targetQoSClass() forced = %v; want %v", got, qosGuaranteed)
+	}
+	if got := preserve.targetQoSClass(Policy{QoSClass: qosBurstable}, qosGuaranteed); got != qosBurstable {
+		t.Errorf("targetQoSClass() forced Burstable = %v; want %v", got, qosBurstable)
+	}
+}
+
+func TestApplyQoS(t *testing.T) {
+	c := Recommendation{TargetQoSClass: qosGuaranteed, NewCPUReqM: 1500, NewMemReqMB: 3000, NewMemLimitMB: 3500, TargetMemLimitMB: 3500}
+	applyQoS(&c)
+	if c.NewCPULimitM != 1500 || c.NewMemReqMB != 3500 || c.TargetMemReqMB != 3500 {
+		t.Errorf("applyQoS() Guaranteed = {%v %v %v}; want {1500 3500 3500}", c.NewCPULimitM, c.NewMemReqMB, c.TargetMemReqMB)
+	}
+
+	c = Recommendation{TargetQoSClass: qosBurstable, NewCPUReqM: 1500, NewCPULimitM: 2000, NewMemReqMB: 3000, NewMemLimitMB: 3500}
+	applyQoS(&c)
+	if c.NewCPULimitM != 0 || c.NewMemReqMB != 3000 {
+		t.Errorf("applyQoS() Burstable = {%v %v}; want {0 3000}", c.NewCPULimitM, c.NewMemReqMB)
+	}
+
+	c = Recommendation{TargetQoSClass: qosBurstable, CPULimitM: 1000, NewCPUReqM: 1500, NewMemReqMB: 3000, NewMemLimitMB: 3500}
+	applyQoS(&c)
+	if c.NewCPULimitM != 1500 {
+		t.Errorf("applyQoS() Burstable with a CPU limit below the new request = %v; want 1500", c.NewCPULimitM)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"math"
 	"regexp"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -24,6 +25,7 @@ type Recommendation struct {
 	NewCPUReqM        float64
 	NewMemReqMB       float64
 	NewMemLimitMB     float64
+	NewCPULimitM      float64
 	GainCPUReqM       float64
 	GainMemReqMB      float64
 	//details
@@ -46,14 +48,118 @@ type Recommendation struct {
 	JVMYoungGenMinMB        float64
 	JVMYoungGenMaxAfterGCMB float64
 	JVMYoungGenMaxMB        float64
+	//gradual step-down (New* values are the next step)
+	TargetCPUReqM    float64
+	TargetMemReqMB   float64
+	TargetMemLimitMB float64
+	StepsLeft        int
+	//under-provisioned container (usage above the current request)
+	RiskFix bool
+	//QoS class
+	QoSClass       string
+	TargetQoSClass string
+	//seasonality
+	CPUProfile    string
+	CPUPeakBucket string
+	MemProfile    string
+	MemPeakBucket string
+	//memory growth trend
+	MemSlopeMBPerDay       float64
+	JVMOldGenSlopeMBPerDay float64
+	LeakSuspected          bool
+	//data window used for the stats (history, rollout or history-fallback) and its start
+	DataWindow string
+	DataSince  time.Time
+	//max CPU during the warm-up after the container starts (excluded from the request)
+	CPUStartupPeakM float64
+	//skew between the pods (highest / median pod percentile) and outlier pods
+	CPUSkew     float64
+	MemSkew     float64
+	OutlierPods []string
+	//role or ordinal of a StatefulSet split per role/ordinal
+	Role string
+	//HPA scaling the pod group, replicas averaged over the history (used for the gains)
+	//and target utilizations with the suggested ones keeping the same scaling
+	HPA                    string
+	AvgReplicas            float64
+	HPATargetCPUPercent    float64
+	HPASuggestedCPUPercent float64
+	HPATargetMemPercent    float64
+	HPASuggestedMemPercent float64
+	//fit of the recommended pod on the nodes (ok, too-large, limit-too-large or large-fraction) and smallest node pool it fits on
+	NodeFit          string
+	SmallestNodePool string
+	//cost: node pool running the pods, owning team, prices and monthly cost delta of the gains
+	NodePool       string
+	Team           string
+	PriceVCPUHour  float64
+	PriceGiBHour   float64
+	MonthlySavings float64
+	//JVM metrics dialect the JVM stats come from
+	JVMDialect string
+	//JVM off-heap budget (non-heap, buffers and thread stacks) and memory left outside of the new heap and the budget with the new limit
+	OffHeapBudgetMB  float64
+	NativeHeadroomMB float64
+	//recommended JVM heap flags consistent with the new memory limit, as options written under JVMOptionsKey in the helm values
+	NewJVMXmxMB            float64
+	NewJVMMaxRAMPercentage float64
+	NewJVMXmnMB            float64
+	JVMOptions             string
+	JVMOptionsKey          string
+	//source of the Xmx% (flag, heap-max or pools) and JVM version
+	JVMXmxSource string
+	JVMVersion   string
+	//% of the time in GC pauses and max GC pause over the data window
+	JVMGCOverheadPercent float64
+	JVMGCMaxPauseMs      float64
+	//JVM health signals above their threshold (name=value separated by |)
+	JVMSignals string
+	//runtime (go, nodejs) sized by a runtime plugin and its memory knob consistent with the new memory limit, written under RuntimeOptionsKey in the helm values
+	Runtime           string
+	RuntimeKnobName   string
+	RuntimeKnobValue  string
+	RuntimeOptionsKey string
+	//Spark application (Spark Operator app name, spark-app-name or spark-app-selector) of a Spark driver or executor
+	SparkApp string
+	//runs of a CronJob or a Job over the data window (sized on a percentile of the peaks of the runs), failed runs and duration of the completed runs
+	BatchRuns            int
+	BatchFailedRuns      int
+	BatchMeanDurationSec float64
+	BatchMaxDurationSec  float64
 }
 
 // GenRecommendation produces a recommendation based on the usage
-func (r *Recommender) GenRecommendation(podGroup PodGroup, usage map[string]ContainerUsage, jvmUsage map[string]JVMContainerUsage, limits map[string]ContainerLimits) []Recommendation {
+func (r *Recommender) GenRecommendation(podGroup PodGroup, usage map[string]ContainerUsage, jvmUsage map[string]JVMContainerUsage, runtimeUsage map[string]RuntimeUsage, batchRuns BatchRuns, limits map[string]ContainerLimits) []Recommendation {
 	result := []Recommendation{}
+	window := r.dataWindowFor(podGroup.Namespace, podGroup.Name)
+	windowDays := time.Since(window.From).Hours() / 24.0
 	//only usage exists, a Bergson concept (only the movement exists)
 	for containerName, elem := range usage {
-		limitAlias, helmValueFileName, untouchMemoryLimit, extraMemoryMargin := r.findExtraParams(podGroup, containerName)
+		policy := r.findPolicy(podGroup, containerName)
+		limitAlias := splitAlias(policy.LimitAlias, podGroup.Role)
+		//the percentiles may be overridden per limit alias
+		cpuPercentile := elem.CPUUsageM.PercentileAt(policy.CPUPercentile)
+		memPercentile := elem.MemUsageMB.PercentileAt(policy.MemPercentile)
+		//batch workloads are sized on a percentile of the peaks of their runs
+		batch := isBatch(podGroup.Kind)
+		if peaks := batchRuns.CPUPeaksM[containerName]; batch && len(peaks) > 0 {
+			cpuPercentile = peakPercentile(peaks, r.BatchPeakPercentile)
+		}
+		if peaks := batchRuns.MemPeaksMB[containerName]; batch && len(peaks) > 0 {
+			memPercentile = peakPercentile(peaks, r.BatchPeakPercentile)
+		}
+		//periodic workloads are sized on their peak bucket (a batch workload only runs in its bucket)
+		cpuProfile, memProfile := SeasonalProfile{}, SeasonalProfile{}
+		if r.Seasonality && !batch {
+			cpuProfile = seasonalProfile(elem.CPUUsageM.samples, policy.CPUPercentile, r.SeasonalityMinStrength, r.SeasonalityLocation)
+			if cpuProfile.Periodic() && cpuProfile.PeakPercentile > cpuPercentile {
+				cpuPercentile = cpuProfile.PeakPercentile
+			}
+			memProfile = seasonalProfile(elem.MemUsageMB.samples, policy.MemPercentile, r.SeasonalityMinStrength, r.SeasonalityLocation)
+			if memProfile.Periodic() && memProfile.PeakPercentile > memPercentile {
+				memPercentile = memProfile.PeakPercentile
+			}
+		}
 
 		// all params that are for sure
 		c := Recommendation{
@@ -64,17 +170,45 @@ func (r *Recommender) GenRecommendation(podGroup PodGroup, usage map[string]Cont
 			ContainerName: containerName,
 			LimitAlias:    limitAlias,
 			//helmValueFileName is not used in the recommendation but can be used to generate helm value files with the
-			HelmValueFileName: helmValueFileName,
+			HelmValueFileName: policy.HelmValueFileName,
+			Team:              policy.Team,
+			JVMOptionsKey:     policy.JVMOptionsKey,
+			RuntimeOptionsKey: policy.RuntimeOptionsKey,
 
 			//details
 			CPUMinM:         elem.CPUUsageM.Min,
 			CPUMeanM:        elem.CPUUsageM.Mean,
-			CPUPercentileM:  elem.CPUUsageM.Percentile,
+			CPUPercentileM:  cpuPercentile,
 			CPUMaxM:         elem.CPUUsageM.Max,
 			MemMinMB:        elem.MemUsageMB.Min,
 			MemMeanMB:       elem.MemUsageMB.Mean,
-			MemPercentileMB: elem.MemUsageMB.Percentile,
+			MemPercentileMB: memPercentile,
 			MemMaxMB:        elem.MemUsageMB.Max,
+			CPUProfile:      cpuProfile.Profile,
+			CPUPeakBucket:   cpuProfile.PeakBucket,
+			MemProfile:      memProfile.Profile,
+			MemPeakBucket:   memProfile.PeakBucket,
+			//trend
+			MemSlopeMBPerDay: elem.MemUsageMB.SlopePerDay,
+			//data window
+			DataWindow: window.Kind,
+			DataSince:  window.From,
+			//warm-up
+			CPUStartupPeakM: elem.CPUStartupPeakM,
+			Role:            podGroup.Role,
+			//batch
+			BatchRuns:            batchRuns.Runs,
+			BatchFailedRuns:      batchRuns.FailedRuns,
+			BatchMeanDurationSec: batchRuns.MeanDurationSec,
+			BatchMaxDurationSec:  batchRuns.MaxDurationSec,
+		}
+		//the sizing is driven by the hottest pod, the outliers are reported to find it (at the percentiles of the policy)
+		var cpuOutliers, memOutliers []string
+		c.CPUSkew, cpuOutliers = podSkew(podPercentiles(elem.CPUPodsM, policy.CPUPercentile), r.OutlierFactor)
+		c.MemSkew, memOutliers = podSkew(podPercentiles(elem.MemPodsMB, policy.MemPercentile), r.OutlierFactor)
+		c.OutlierPods = mergeOutliers(cpuOutliers, memOutliers)
+		if len(c.OutlierPods) > 0 {
+			log.Info("Outlier pods ", c.OutlierPods, " for pod group ", podGroup.Name, " container ", containerName, " (CPU skew ", c.CPUSkew, " Mem skew ", c.MemSkew, ")")
 		}
 		// Check if the containerName exists in the limits map
 		if val, ok := limits[containerName]; ok {
@@ -85,23 +219,29 @@ func (r *Recommender) GenRecommendation(podGroup PodGroup, usage map[string]Cont
 		} else {
 			log.Warn("No limits found for container ", containerName, " in pod group ", podGroup.Name, " will recommend limits based on usage")
 		}
+		c.QoSClass = qosClass(limits[containerName])
+		c.TargetQoSClass = r.targetQoSClass(policy, c.QoSClass)
 		//CPU Recommendation Req & NO CPU limit
-		if elem.CPUUsageM.Percentile > r.PodMinCPUMillicores {
-			c.NewCPUReqM = elem.CPUUsageM.Percentile
+		if cpuPercentile > policy.PodMinCPUMillicores {
+			c.NewCPUReqM = cpuPercentile
 		} else {
-			c.NewCPUReqM = r.PodMinCPUMillicores
+			c.NewCPUReqM = policy.PodMinCPUMillicores
 		}
 
-		if val, ok := jvmUsage[containerName]; ok && val.OldGenUsageMB.Max > 0 && c.MemLimitMB > 0 {
+		val, isJVM := jvmUsage[containerName]
+		isJVM = isJVM && val.OldGenUsageMB.Max > 0 && c.MemLimitMB > 0
+		if isJVM {
 			//WE WILL RECOMMEND Mem REQ and Mem LIMIT based on JVM only if JVM metrics are available
-			//calculate JVM Xmx
-			c.JVMXmxPercent = (val.YoungPoolMB + val.OldPoolMB) * 100.0 / c.MemLimitMB
+			//calculate JVM Xmx from the explicit max heap, inferred from the pools max otherwise
+			c.JVMXmxPercent, c.JVMXmxSource = jvmXmxPercent(val, c.MemLimitMB)
 			if c.JVMXmxPercent < 1.0 {
-				log.Warn("Xmx% is incorrect, recommendation will be skipped for pod ", podGroup.Name, "  container ", containerName, " instant OldPoolMB ", val.OldPoolMB)
+				log.Warn("Xmx% is incorrect, recommendation will be skipped for pod ", podGroup.Name, "  container ", containerName, " instant OldPoolMB ", val.OldPoolMB, " source ", c.JVMXmxSource)
 				continue
 			}
 			c.JVMYoungGenMB = val.YoungGenSizeMB
-			c.JVMAllocationStalls = val.AllocationStall
+			c.JVMAllocationStalls = allocationStalls(val.Signals)
+			c.JVMDialect = val.Dialect
+			c.JVMVersion = val.Version
 			c.JVMOldGenMinMB = val.OldGenUsageMB.Min
 			c.JVMOldGenMaxMB = val.OldGenUsageMB.Max
 			c.JVMOldGenMaxAfterFullGCMB = val.OldGenUsageAfterGcMB
@@ -113,85 +253,135 @@ func (r *Recommender) GenRecommendation(podGroup PodGroup, usage map[string]Cont
 			// new Req   = new Limit * 85%
 			//static memory is the Heap memory containing JVM metadata which is the baseline of the JVM graph (ie the min of the OldGen usage)
 			//transaction memory is the Heap memory that cannot be garbage collected during transactions (ie the max after full GC of the OldGen usage)
-			maxTransactionVsStaticMemory := math.Max(c.JVMOldGenMaxAfterFullGCMB*100.0/r.TargetMemOldGenUsagePercent, r.TargetMemStaticMaxRatio*c.JVMOldGenMinMB)
+			//a growing Old Gen after GC is a suspected leak, the transaction memory is then the forecast at the horizon
+			transactionMemory := c.JVMOldGenMaxAfterFullGCMB
+			c.JVMOldGenSlopeMBPerDay = val.OldGenAfterGcSlopeMBPerDay
+			if leakSuspected(c.JVMOldGenSlopeMBPerDay, c.JVMOldGenMaxAfterFullGCMB, windowDays, r.LeakMinGrowthPercent) {
+				log.Warn("Old Gen after GC is growing by ", c.JVMOldGenSlopeMBPerDay, " MiB/day, suspected leak for pod ", podGroup.Name, " container ", containerName)
+				c.LeakSuspected = true
+				transactionMemory = r.forecast(transactionMemory, c.JVMOldGenSlopeMBPerDay)
+			}
+			maxTransactionVsStaticMemory := math.Max(transactionMemory*100.0/r.TargetMemOldGenUsagePercent, r.TargetMemStaticMaxRatio*c.JVMOldGenMinMB)
 
 			//Old algo
 			//Cons does not work well with Java 24 with ZGC Young Generation which is sometimes = to Xmx and with ElasticSearch which has no Young Generation value
 			// newXmx := c.JVMYoungGenMB + maxTransactionVsStaticMemory
 			//New algo
 			newXmx := c.JVMYoungGenMaxAfterGCMB + maxTransactionVsStaticMemory
-			newLimit := newXmx * 100.0 / c.JVMXmxPercent
+			//a low Old Gen after GC may come from constant collections: the heap is not shrunk (or grown) under GC pressure
+			c.JVMGCOverheadPercent = val.GCOverheadPercent
+			c.JVMGCMaxPauseMs = val.GCMaxPauseSeconds * 1000.0
+			if minXmx := r.gcPressureMinXmx(c.JVMGCOverheadPercent, c.JVMXmxPercent*c.MemLimitMB/100.0); newXmx < minXmx {
+				log.Warn("GC overhead ", c.JVMGCOverheadPercent, "% (max pause ", c.JVMGCMaxPauseMs, " ms), heap kept at ", minXmx, " MiB instead of ", newXmx, " MiB for pod ", podGroup.Name, " container ", containerName)
+				newXmx = minXmx
+			}
+			//fired JVM health signals may also keep or grow the heap
+			fired := r.firedJVMSignals(val.Signals)
+			c.JVMSignals = formatJVMSignals(fired)
+			if minXmx := r.signalsMinXmx(fired, c.JVMXmxPercent*c.MemLimitMB/100.0); newXmx < minXmx {
+				log.Warn("JVM signals ", c.JVMSignals, ", heap kept at ", minXmx, " MiB instead of ", newXmx, " MiB for pod ", podGroup.Name, " container ", containerName)
+				newXmx = minXmx
+			}
+			//the limit also leaves room for the off-heap budget outside of the heap
+			c.OffHeapBudgetMB = r.offHeapBudgetMB(val)
+			newLimit := jvmLimitMB(newXmx, c.JVMXmxPercent, c.OffHeapBudgetMB)
+			if currentHeadroom := nativeHeadroomMB(c.MemLimitMB, c.JVMXmxPercent, c.OffHeapBudgetMB); c.OffHeapBudgetMB > 0 && currentHeadroom < 0 {
+				log.Warn("Off-heap budget ", c.OffHeapBudgetMB, " MiB exceeds the memory outside of the heap by ", -currentHeadroom, " MiB for pod ", podGroup.Name, " container ", containerName)
+			}
 			//extra protective measure
-			//for Java processes the min Xmx is 512MB, so we will not recommend less than that
-			//and if the new Limit is between 300MB and 1GB, we will recommend 1GB
-			if newLimit < 300.0 {
-				newLimit = 512.0
-			} else if newLimit < 1024.0 {
-				newLimit = 1024.0
+			//for Java processes the min Xmx is 512MB (by default), so we will not recommend less than that
+			//and if the new Limit is between 300MB and 1GB (by default), we will recommend 1GB
+			if newLimit < policy.JVMFloorThresholdMB {
+				newLimit = policy.JVMMinLimitMB
+			} else if newLimit < policy.JVMFloorLimitMB {
+				newLimit = policy.JVMFloorLimitMB
 			}
 
 			c.NewMemLimitMB = newLimit
-			c.NewMemReqMB = c.NewMemLimitMB * r.TargetMemLimitToReqPercent / 100.0
+			c.NewMemReqMB = c.NewMemLimitMB * policy.MemLimitToReqPercent / 100.0
 			//only applied if extraMemoryMargin > 0 and for the limit (req remains the same)
-			if extraMemoryMargin > 0 {
-				c.NewMemLimitMB = float64(100+extraMemoryMargin) * c.NewMemLimitMB / 100.0
+			if policy.ExtraMemoryMargin > 0 {
+				c.NewMemLimitMB = float64(100+policy.ExtraMemoryMargin) * c.NewMemLimitMB / 100.0
 			}
+			c.NewJVMXmxMB = newXmx
 		} else {
 			//WE WILL RECOMMEND Mem REQ and Mem LIMIT based on USAGE
-			if elem.MemUsageMB.Percentile > r.PodMinMemoryMb {
-				c.NewMemReqMB = elem.MemUsageMB.Percentile
+			//a growing working set is a suspected leak
+			if leakSuspected(c.MemSlopeMBPerDay, c.MemMeanMB, windowDays, r.LeakMinGrowthPercent) {
+				log.Warn("Memory is growing by ", c.MemSlopeMBPerDay, " MiB/day, suspected leak for pod ", podGroup.Name, " container ", containerName)
+				c.LeakSuspected = true
+			}
+			if memPercentile > policy.PodMinMemoryMb {
+				c.NewMemReqMB = memPercentile
 			} else {
-				c.NewMemReqMB = r.PodMinMemoryMb
+				c.NewMemReqMB = policy.PodMinMemoryMb
 			}
-			if untouchMemoryLimit {
+			if policy.UntouchMemoryLimit {
 				log.Info("Untouching memory limit for pod ", podGroup.Name, " container ", containerName, " using LimitAlias ", limitAlias)
 				c.NewMemLimitMB = c.MemLimitMB //keep the current limit
 			} else {
 				log.Debug("Recommending memory limit for pod ", podGroup.Name, " container ", containerName, " using LimitAlias ", limitAlias)
 				//calculate Mem Limit as the max mem usage + some buffer
-				c.NewMemLimitMB = elem.MemUsageMB.Max * 100.0 / r.TargetMemLimitToReqPercent
+				//a growing working set is sized on the forecast at the horizon
+				memMax := elem.MemUsageMB.Max
+				if c.LeakSuspected {
+					memMax = r.forecast(memMax, c.MemSlopeMBPerDay)
+				}
+				c.NewMemLimitMB = memMax * 100.0 / policy.MemLimitToReqPercent
 				//harness the Mem Limit to be at least the PodMinMemoryMb
-				if c.NewMemLimitMB < r.PodMinMemoryMb {
-					c.NewMemLimitMB = r.PodMinMemoryMb
+				if c.NewMemLimitMB < policy.PodMinMemoryMb {
+					c.NewMemLimitMB = policy.PodMinMemoryMb
 				}
 				//only applied if extraMemoryMargin > 0 and for the limit (req remains the same)
-				if extraMemoryMargin > 0 {
-					c.NewMemLimitMB = float64(100+extraMemoryMargin) * c.NewMemLimitMB / 100.0
+				if policy.ExtraMemoryMargin > 0 {
+					c.NewMemLimitMB = float64(100+policy.ExtraMemoryMargin) * c.NewMemLimitMB / 100.0
+				}
+				//the runtime managed memory must fit under its knob
+				if minLimit := r.runtimeMinLimitMB(runtimeUsage[containerName]); c.NewMemLimitMB < minLimit {
+					log.Info("Runtime ", runtimeUsage[containerName].Runtime, " memory limit raised to ", minLimit, " MiB instead of ", c.NewMemLimitMB, " MiB for pod ", podGroup.Name, " container ", containerName)
+					c.NewMemLimitMB = minLimit
 				}
 			}
 		}
 
-		//calculate Gain
-		c.GainCPUReqM = float64(podGroup.Count) * (c.CPUReqM - c.NewCPUReqM)
-		c.GainMemReqMB = float64(podGroup.Count) * (c.MemReqMB - c.NewMemReqMB)
+		//hard bounds of the policy, then cap the decrease per run, round and keep the previous values when the change is not significant
+		policy.applyBounds(&c)
+		r.stepDown(&c, isJVM)
+		r.stabilize(&c, policy.UntouchMemoryLimit)
+		//hard bounds win over rounding and hysteresis
+		policy.applyBounds(&c)
+		//requests == limits for Guaranteed
+		applyQoS(&c)
+		//the JVM flags follow the final memory limit
+		if isJVM {
+			r.applyJVMFlags(&c, policy.JVMOptions)
+		} else {
+			r.applyRuntimeKnob(&c, runtimeUsage[containerName], policy.RuntimeOptions)
+		}
+
+		r.applyGains(&c, float64(podGroup.Count))
 		result = append(result, c)
 	}
 	return result
 }
 
-func (r *Recommender) findExtraParams(podGroup PodGroup, containerName string) (string, string, bool, int) {
-	for _, extra := range r.ExtraParams {
-		//if the Pod name respect the pod name regex
-		//^ is the start of the string, $ is the end of the string
-		matchPodGroup, _ := regexp.MatchString("^"+extra.Pod+"$", podGroup.Name)
-		matchContainer, _ := regexp.MatchString("^"+extra.Container+"$", containerName)
-		if matchPodGroup && matchContainer {
-			definitiveHelmValueFileName := extra.HelmValueFileName
-			//check if the HelmValueFileName contains a $1 pattern to be replaced by the PodGroup regex capture group
-			definitiveHelmValueFileName = replaceCaptureGroup(extra.Pod, podGroup.Name, definitiveHelmValueFileName)
-
-			// re := regexp.MustCompile(extra.Pod)
-			// matches := re.FindStringSubmatch(podGroup.Name)
-			// if len(matches) > 1 {
-			// 	//there is at least one capture group
-			// 	//replace $1 with the first capture group
-			// 	definitiveHelmValueFileName = re.ReplaceAllString(definitiveHelmValueFileName, matches[1])
-			// }
-			definitiveHelmValueFileName = "helm-values-" + definitiveHelmValueFileName
-			return extra.LimitAlias, definitiveHelmValueFileName, extra.UntouchMemoryLimit, extra.ExtraMemoryMargin //return the limit alias
-		}
-	}
-	return "NA", "", false, 0 //if no match found, return NA and false
+// applyGains calculates the gains on the replicas and flags the risk fixes
+func (r *Recommender) applyGains(c *Recommendation, replicas float64) {
+	c.GainCPUReqM = replicas * (c.CPUReqM - c.NewCPUReqM)
+	c.GainMemReqMB = replicas * (c.MemReqMB - c.NewMemReqMB)
+	c.RiskFix = r.cpuRiskFix(*c) || r.memRiskFix(*c)
+}
+
+// cpuRiskFix returns true if the CPU request is raised by more than UpsizeMinCPUReqM
+// a container without request is not under-provisioned
+func (r *Recommender) cpuRiskFix(c Recommendation) bool {
+	return c.CPUReqM > 0 && -c.GainCPUReqM > r.UpsizeMinCPUReqM
+}
+
+// memRiskFix returns true if the Mem request is raised by more than UpsizeMinMemReqMB
+// a container without request is not under-provisioned
+func (r *Recommender) memRiskFix(c Recommendation) bool {
+	return c.MemReqMB > 0 && -c.GainMemReqMB > r.UpsizeMinMemReqMB
 }
 
 func replaceCaptureGroup(pattern, str, replacement string) string {
@@ -204,3 +394,11 @@ func replaceCaptureGroup(pattern, str, replacement string) string {
 	}
 	return replacement
 }
+
+// forecast returns the value expected at the forecast horizon given its growth per day (value itself if no horizon)
+func (r *Recommender) forecast(value, slopePerDay float64) float64 {
+	if r.ForecastHorizon <= 0 || slopePerDay <= 0 {
+		return value
+	}
+	return value + slopePerDay*r.ForecastHorizon.Hours()/24.0
+}
@@ -15,10 +15,10 @@ func TestFindLimitAlias3_MatchFound(t *testing.T) {
 	podGroup := PodGroup{Name: "tmp-coucou-1"}
 	containerName := "tmp-container"
 
-	got, _, _, _ := r.findExtraParams(podGroup, containerName)
+	got := r.findPolicy(podGroup, containerName).LimitAlias
 	want := "res.tmp"
 	if got != want {
-		t.Errorf("findExtraParams() = %v, want %v", got, want)
+		t.Errorf("findPolicy() LimitAlias = %v, want %v", got, want)
 	}
 }
 
@@ -32,10 +32,10 @@ func TestFindLimitAlias2_MatchFound(t *testing.T) {
 	podGroup := PodGroup{Name: "tmp-editor-slave-1"}
 	containerName := "tmp-editor"
 
-	got, _, _, _ := r.findExtraParams(podGroup, containerName)
+	got := r.findPolicy(podGroup, containerName).LimitAlias
 	want := "res.editor"
 	if got != want {
-		t.Errorf("findExtraParams() = %v, want %v", got, want)
+		t.Errorf("findPolicy() LimitAlias = %v, want %v", got, want)
 	}
 }
 
@@ -49,10 +49,10 @@ func TestFindLimitAlias_MatchFound(t *testing.T) {
 	podGroup := PodGroup{Name: "my-pod"}
 	containerName := "my-container"
 
-	got, _, _, _ := r.findExtraParams(podGroup, containerName)
+	got := r.findPolicy(podGroup, containerName).LimitAlias
 	want := "alias1"
 	if got != want {
-		t.Errorf("findExtraParams() = %v, want %v", got, want)
+		t.Errorf("findPolicy() LimitAlias = %v, want %v", got, want)
 	}
 }
 
@@ -65,10 +65,10 @@ func TestFindLimitAlias_MatchWithRegex(t *testing.T) {
 	podGroup := PodGroup{Name: "my-pod"}
 	containerName := "container-123"
 
-	got, _, _, _ := r.findExtraParams(podGroup, containerName)
+	got := r.findPolicy(podGroup, containerName).LimitAlias
 	want := "regex-alias"
 	if got != want {
-		t.Errorf("findExtraParams() = %v, want %v", got, want)
+		t.Errorf("findPolicy() LimitAlias = %v, want %v", got, want)
 	}
 }
 
@@ -81,10 +81,10 @@ func TestFindLimitAlias_NoMatch(t *testing.T) {
 	podGroup := PodGroup{Name: "baz"}
 	containerName := "qux"
 
-	got, _, _, _ := r.findExtraParams(podGroup, containerName)
+	got := r.findPolicy(podGroup, containerName).LimitAlias
 	want := "NA"
 	if got != want {
-		t.Errorf("findExtraParams() = %v, want %v", got, want)
+		t.Errorf("findPolicy() LimitAlias = %v, want %v", got, want)
 	}
 }
 
@@ -95,10 +95,10 @@ func TestFindLimitAlias_EmptyAliases(t *testing.T) {
 	podGroup := PodGroup{Name: "any"}
 	containerName := "any"
 
-	got, _, _, _ := r.findExtraParams(podGroup, containerName)
+	got := r.findPolicy(podGroup, containerName).LimitAlias
 	want := "NA"
 	if got != want {
-		t.Errorf("findExtraParams() = %v, want %v", got, want)
+		t.Errorf("findPolicy() LimitAlias = %v, want %v", got, want)
 	}
 }
 
@@ -123,3 +123,65 @@ func TestReplaceCaptureGroup(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchExtraParams(t *testing.T) {
+	r := &Recommender{
+		ExtraParams: []utils.PodContainerExtraParams{
+			{Pod: "tmp-(coucou)-\\d", Container: "tmp-container", LimitAlias: "res.tmp", HelmValueFileName: "$1"},
+			{Pod: "tmp-.*", Container: "tmp-container", LimitAlias: "res.other", HelmValueFileName: "other"},
+		},
+	}
+	extra, helmValueFileName, found := r.matchExtraParams(PodGroup{Name: "tmp-coucou-1"}, "tmp-container")
+	if !found || extra.LimitAlias != "res.tmp" || helmValueFileName != "helm-values-coucou" {
+		t.Errorf("matchExtraParams() = %v %v %v; want res.tmp helm-values-coucou true", extra.LimitAlias, helmValueFileName, found)
+	}
+	if _, _, found := r.matchExtraParams(PodGroup{Name: "tmp-coucou-1"}, "sidecar"); found {
+		t.Errorf("matchExtraParams() found a match for an unknown container")
+	}
+}
+
+func TestFindPolicy(t *testing.T) {
+	r := &Recommender{
+		TargetCPUPercentile:        90,
+		TargetMemPercentile:        90,
+		TargetMemLimitToReqPercent: 85,
+		PodMinCPUMillicores:        5,
+		PodMinMemoryMb:             50,
+		JVMMinLimitMB:              512,
+		JVMFloorLimitMB:            1024,
+		JVMFloorThresholdMB:        300,
+		ExtraParams: []utils.PodContainerExtraParams{
+			{Pod: "latency-.*", Container: "app", LimitAlias: "res.latency", CPUPercentile: 99, JVMFloorLimitMB: 2048, JVMFloorThresholdMB: 600, MinMemLimitMB: 4096},
+			{Pod: "batch-.*", Container: "worker", LimitAlias: "res.batch", CPUPercentile: 50, MemPercentile: 50},
+		},
+	}
+
+	latency := r.findPolicy(PodGroup{Name: "latency-api"}, "app")
+	if latency.LimitAlias != "res.latency" || latency.CPUPercentile != 99 || latency.MemPercentile != 90 || latency.JVMFloorLimitMB != 2048 || latency.JVMFloorThresholdMB != 600 || latency.JVMMinLimitMB != 512 || latency.MinMemLimitMB != 4096 {
+		t.Errorf("findPolicy() latency = %+v", latency)
+	}
+	batch := r.findPolicy(PodGroup{Name: "batch-report"}, "worker")
+	if batch.LimitAlias != "res.batch" || batch.CPUPercentile != 50 || batch.MemPercentile != 50 || batch.MemLimitToReqPercent != 85 {
+		t.Errorf("findPolicy() batch = %+v", batch)
+	}
+	global := r.findPolicy(PodGroup{Name: "other"}, "other")
+	if global.LimitAlias != "NA" || global.CPUPercentile != 90 || global.PodMinMemoryMb != 50 || global.MaxCPUReqM != 0 || global.JVMFloorThresholdMB != 300 {
+		t.Errorf("findPolicy() global = %+v", global)
+	}
+}
+
+func TestApplyBounds(t *testing.T) {
+	policy := Policy{MinCPUReqM: 100, MaxCPUReqM: 2000, MinMemReqMB: 256, MaxMemReqMB: 1024, MinMemLimitMB: 512, MaxMemLimitMB: 900}
+
+	c := Recommendation{NewCPUReqM: 20, NewMemReqMB: 2000, NewMemLimitMB: 3000}
+	policy.applyBounds(&c)
+	if c.NewCPUReqM != 100 || c.NewMemReqMB != 900 || c.NewMemLimitMB != 900 {
+		t.Errorf("applyBounds() = {%v %v %v}; want {100 900 900}", c.NewCPUReqM, c.NewMemReqMB, c.NewMemLimitMB)
+	}
+
+	c = Recommendation{NewCPUReqM: 5000, NewMemReqMB: 100, NewMemLimitMB: 200}
+	policy.applyBounds(&c)
+	if c.NewCPUReqM != 2000 || c.NewMemReqMB != 256 || c.NewMemLimitMB != 512 {
+		t.Errorf("applyBounds() = {%v %v %v}; want {2000 256 512}", c.NewCPUReqM, c.NewMemReqMB, c.NewMemLimitMB)
+	}
+}
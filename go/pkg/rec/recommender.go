@@ -13,6 +13,69 @@ type Recommender struct {
 	History, Interval                                                                                                                                               time.Duration
 	PodMinCPUMillicores, PodMinMemoryMb, TargetCPUPercentile, TargetMemPercentile, TargetMemLimitToReqPercent, TargetMemOldGenUsagePercent, TargetMemStaticMaxRatio float64
 	ExtraParams                                                                                                                                                     []utils.PodContainerExtraParams
+	//stability of the recommendations between runs
+	CPURoundingStepM, MemRoundingStepMB, HysteresisPercent float64
+	RoundingPowerOfTwo                                     bool
+	lastRun                                                map[string]Recommendation
+	//gradual step-down of the recommendations (max decrease per run)
+	MaxStepDownCPUPercent, MaxStepDownMemPercent, MaxStepDownJVMCPUPercent, MaxStepDownJVMMemPercent float64
+	//min gain (downsizing) and min missing request (upsizing) to emit a recommendation
+	MinGainCPUReqM, MinGainMemReqMB, UpsizeMinCPUReqM, UpsizeMinMemReqMB float64
+	//JVM limit floors (min limit and floor applied to limits under 1GB)
+	JVMMinLimitMB, JVMFloorLimitMB, JVMFloorThresholdMB float64
+	//preserve (keep Guaranteed pods Guaranteed) or ignore the current QoS class
+	QoSPolicy string
+	//seasonality-aware sizing on the peak bucket (day of week x hour of day)
+	Seasonality            bool
+	SeasonalityMinStrength float64
+	SeasonalityLocation    *time.Location
+	//memory growth trend: leak detection and optional sizing on the forecast at the horizon
+	LeakMinGrowthPercent float64
+	ForecastHorizon      time.Duration
+	//samples inside these windows are dropped (dropped counts by window index for the run metadata)
+	ExclusionWindows []utils.ExclusionWindow
+	excludedSamples  map[int]int
+	//stats restricted to the period after the last image or spec change (if at least MinRolloutData remains)
+	SinceLastRollout bool
+	MinRolloutData   time.Duration
+	dataWindows      map[string]dataWindow
+	//CPU samples during the warm-up after each container start are excluded (0 means disabled)
+	WarmupExclusion time.Duration
+	//pods above OutlierFactor times the median pod percentile are reported as outliers
+	OutlierFactor float64
+	//StatefulSets recommendations per role (pod label) or per ordinal
+	StsRoleLabel  string
+	StsPerOrdinal bool
+	//node pools (nodes grouped by label) checked against the recommended pods
+	NodePoolLabel      string
+	NodeFitMaxFraction float64
+	nodePoolOf         map[string]string
+	//prices per vCPU-hour and GiB-hour (0 means no cost reporting) overridden per namespace or node pool
+	PriceVCPUHour, PriceGiBHour float64
+	Prices                      []utils.Price
+	//JVM metrics dialect (auto-detected per pod group, or forced to jmx, micrometer or otel)
+	JVMDialect string
+	//recommended JVM heap flag (percentage for -XX:MaxRAMPercentage or xmx for -Xmx) and optional -Xmn
+	JVMFlagStyle    string
+	JVMRecommendXmn bool
+	//metric of the HotSpot MaxHeapSize flag in bytes (e.g. exposed by a VM flags exporter), preferred to the heap max
+	JVMMaxHeapFlagMetric string
+	//above GCOverheadMaxPercent of the time in GC pauses the heap is not shrunk, and grown by GCOverheadGrowPercent if set
+	GCOverheadMaxPercent  float64
+	GCOverheadGrowPercent float64
+	//stack size of a JVM thread (-Xss) counted in the off-heap budget
+	JVMThreadStackKB float64
+	//JVM health signals queried for each JVM container
+	JVMSignals []utils.JVMSignal
+	//JVM heap pools mapping overriding the built-in one (by pool name)
+	GCPools []utils.GCPool
+	//share of the container limit given to GOMEMLIMIT (Go) and --max-old-space-size (Node.js)
+	GoMemLimitPercent   float64
+	NodeOldSpacePercent float64
+	//share of the Spark memory (heap) added as overhead when no JVM stats are available (spark.kubernetes.memoryOverheadFactor)
+	SparkMemoryOverheadFactor float64
+	//percentile of the peaks of the runs sizing the CronJobs and Jobs
+	BatchPeakPercentile float64
 }
 
 // NewRecommender creates a new Recommender
@@ -30,9 +93,60 @@ func NewRecommender(extraParams []utils.PodContainerExtraParams) *Recommender {
 		TargetMemOldGenUsagePercent: utils.GetFloat64Env("TARGET_MEM_OLD_GEN_USAGE_PERCENT", 65),
 		TargetMemStaticMaxRatio:     utils.GetFloat64Env("TARGET_MEM_STATIC_MAX_RATIO", 3),
 		ExtraParams:                 extraParams,
+		CPURoundingStepM:            utils.GetFloat64Env("ROUNDING_STEP_CPU_M", 0),
+		MemRoundingStepMB:           utils.GetFloat64Env("ROUNDING_STEP_MEM_MB", 0),
+		RoundingPowerOfTwo:          utils.GetBoolEnv("ROUNDING_POWER_OF_TWO", false),
+		HysteresisPercent:           utils.GetFloat64Env("HYSTERESIS_PERCENT", 0),
+		MaxStepDownCPUPercent:       utils.GetFloat64Env("MAX_STEP_DOWN_CPU_PERCENT", 0),
+		MaxStepDownMemPercent:       utils.GetFloat64Env("MAX_STEP_DOWN_MEM_PERCENT", 0),
+		MaxStepDownJVMCPUPercent:    utils.GetFloat64Env("MAX_STEP_DOWN_JVM_CPU_PERCENT", 0),
+		MaxStepDownJVMMemPercent:    utils.GetFloat64Env("MAX_STEP_DOWN_JVM_MEM_PERCENT", 0),
+		MinGainCPUReqM:              utils.GetFloat64Env("MIN_GAIN_CPU_M", 50),
+		MinGainMemReqMB:             utils.GetFloat64Env("MIN_GAIN_MEM_MB", 100),
+		UpsizeMinCPUReqM:            utils.GetFloat64Env("UPSIZE_MIN_CPU_M", 50),
+		UpsizeMinMemReqMB:           utils.GetFloat64Env("UPSIZE_MIN_MEM_MB", 100),
+		JVMMinLimitMB:               utils.GetFloat64Env("JVM_MIN_LIMIT_MB", 512),
+		JVMFloorLimitMB:             utils.GetFloat64Env("JVM_FLOOR_LIMIT_MB", 1024),
+		JVMFloorThresholdMB:         utils.GetFloat64Env("JVM_FLOOR_THRESHOLD_MB", 300),
+		QoSPolicy:                   utils.GetStringEnv("QOS_POLICY", qosPreserve),
+		Seasonality:                 utils.GetBoolEnv("SEASONALITY", false),
+		SeasonalityMinStrength:      utils.GetFloat64Env("SEASONALITY_MIN_STRENGTH", 0.3),
+		SeasonalityLocation:         loadLocation(utils.GetStringEnv("SEASONALITY_TIMEZONE", "UTC")),
+		LeakMinGrowthPercent:        utils.GetFloat64Env("LEAK_MIN_GROWTH_PERCENT", 20),
+		ForecastHorizon:             utils.GetDurationEnv("FORECAST_HORIZON", 0),
+		SinceLastRollout:            utils.GetBoolEnv("SINCE_LAST_ROLLOUT", false),
+		MinRolloutData:              utils.GetDurationEnv("MIN_ROLLOUT_DATA", 24*time.Hour),
+		WarmupExclusion:             utils.GetDurationEnv("WARMUP_EXCLUSION", 0),
+		OutlierFactor:               utils.GetFloat64Env("OUTLIER_FACTOR", 2),
+		StsRoleLabel:                utils.GetStringEnv("STS_ROLE_LABEL", ""),
+		StsPerOrdinal:               utils.GetBoolEnv("STS_PER_ORDINAL", false),
+		NodePoolLabel:               utils.GetStringEnv("NODE_POOL_LABEL", "node.kubernetes.io/instance-type"),
+		NodeFitMaxFraction:          utils.GetFloat64Env("NODE_FIT_MAX_FRACTION", 0.5),
+		JVMDialect:                  utils.GetStringEnv("JVM_DIALECT", jvmDialectAuto),
+		JVMThreadStackKB:            utils.GetFloat64Env("JVM_THREAD_STACK_KB", 1024),
+		JVMMaxHeapFlagMetric:        utils.GetStringEnv("JVM_MAX_HEAP_FLAG_METRIC", ""),
+		GCOverheadMaxPercent:        utils.GetFloat64Env("GC_OVERHEAD_MAX_PERCENT", 10),
+		GCOverheadGrowPercent:       utils.GetFloat64Env("GC_OVERHEAD_GROW_PERCENT", 0),
+		JVMFlagStyle:                utils.GetStringEnv("JVM_FLAG_STYLE", jvmFlagStylePercentage),
+		JVMRecommendXmn:             utils.GetBoolEnv("JVM_RECOMMEND_XMN", false),
+		GoMemLimitPercent:           utils.GetFloat64Env("GO_MEMLIMIT_PERCENT", 90),
+		NodeOldSpacePercent:         utils.GetFloat64Env("NODE_OLD_SPACE_PERCENT", 75),
+		SparkMemoryOverheadFactor:   utils.GetFloat64Env("SPARK_MEMORY_OVERHEAD_FACTOR", 0.1),
+		BatchPeakPercentile:         utils.GetFloat64Env("BATCH_PEAK_PERCENTILE", 90),
+		PriceVCPUHour:               utils.GetFloat64Env("PRICE_VCPU_HOUR", 0),
+		PriceGiBHour:                utils.GetFloat64Env("PRICE_GIB_HOUR", 0),
 	}
 }
 
+func loadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		log.Error("Unknown timezone ", name, " will use UTC, err ", err)
+		return time.UTC
+	}
+	return location
+}
+
 func assemblePrometheusURL() string {
 	promURL := "http://prometheus:9090"
 	promHTTPSchema := utils.GetStringEnv("PROMETHEUS_HTTP_SCHEMA", "http")
@@ -64,4 +178,50 @@ func (r *Recommender) ShowConfig() {
 	log.Infof("TargetMemPercentile: %f", r.TargetMemPercentile)
 	log.Infof("TargetMemLimitToReqPercent: %f", r.TargetMemLimitToReqPercent)
 	log.Infof("TargetMemOldGenUsagePercent: %f", r.TargetMemOldGenUsagePercent)
+	log.Infof("CPURoundingStepM: %f", r.CPURoundingStepM)
+	log.Infof("MemRoundingStepMB: %f", r.MemRoundingStepMB)
+	log.Infof("RoundingPowerOfTwo: %t", r.RoundingPowerOfTwo)
+	log.Infof("HysteresisPercent: %f", r.HysteresisPercent)
+	log.Infof("MaxStepDownCPUPercent: %f", r.MaxStepDownCPUPercent)
+	log.Infof("MaxStepDownMemPercent: %f", r.MaxStepDownMemPercent)
+	log.Infof("MaxStepDownJVMCPUPercent: %f", r.MaxStepDownJVMCPUPercent)
+	log.Infof("MaxStepDownJVMMemPercent: %f", r.MaxStepDownJVMMemPercent)
+	log.Infof("MinGainCPUReqM: %f", r.MinGainCPUReqM)
+	log.Infof("MinGainMemReqMB: %f", r.MinGainMemReqMB)
+	log.Infof("UpsizeMinCPUReqM: %f", r.UpsizeMinCPUReqM)
+	log.Infof("UpsizeMinMemReqMB: %f", r.UpsizeMinMemReqMB)
+	log.Infof("JVMMinLimitMB: %f", r.JVMMinLimitMB)
+	log.Infof("JVMFloorLimitMB: %f", r.JVMFloorLimitMB)
+	log.Infof("JVMFloorThresholdMB: %f", r.JVMFloorThresholdMB)
+	log.Infof("QoSPolicy: %s", r.QoSPolicy)
+	log.Infof("Seasonality: %t", r.Seasonality)
+	log.Infof("SeasonalityMinStrength: %f", r.SeasonalityMinStrength)
+	log.Infof("SeasonalityLocation: %s", r.SeasonalityLocation)
+	log.Infof("LeakMinGrowthPercent: %f", r.LeakMinGrowthPercent)
+	log.Infof("ForecastHorizon: %s", r.ForecastHorizon)
+	log.Infof("ExclusionWindows: %d", len(r.ExclusionWindows))
+	log.Infof("SinceLastRollout: %t", r.SinceLastRollout)
+	log.Infof("MinRolloutData: %s", r.MinRolloutData)
+	log.Infof("WarmupExclusion: %s", r.WarmupExclusion)
+	log.Infof("OutlierFactor: %f", r.OutlierFactor)
+	log.Infof("StsRoleLabel: %s", r.StsRoleLabel)
+	log.Infof("StsPerOrdinal: %t", r.StsPerOrdinal)
+	log.Infof("NodePoolLabel: %s", r.NodePoolLabel)
+	log.Infof("NodeFitMaxFraction: %f", r.NodeFitMaxFraction)
+	log.Infof("PriceVCPUHour: %f", r.PriceVCPUHour)
+	log.Infof("PriceGiBHour: %f", r.PriceGiBHour)
+	log.Infof("Prices: %d overrides", len(r.Prices))
+	log.Infof("JVMDialect: %s", r.JVMDialect)
+	log.Infof("JVMThreadStackKB: %f", r.JVMThreadStackKB)
+	log.Infof("JVMMaxHeapFlagMetric: %s", r.JVMMaxHeapFlagMetric)
+	log.Infof("GCOverheadMaxPercent: %f", r.GCOverheadMaxPercent)
+	log.Infof("GCOverheadGrowPercent: %f", r.GCOverheadGrowPercent)
+	log.Infof("JVMFlagStyle: %s", r.JVMFlagStyle)
+	log.Infof("JVMRecommendXmn: %t", r.JVMRecommendXmn)
+	log.Infof("GCPools: %d overrides", len(r.GCPools))
+	log.Infof("JVMSignals: %d", len(r.JVMSignals))
+	log.Infof("GoMemLimitPercent: %f", r.GoMemLimitPercent)
+	log.Infof("NodeOldSpacePercent: %f", r.NodeOldSpacePercent)
+	log.Infof("SparkMemoryOverheadFactor: %f", r.SparkMemoryOverheadFactor)
+	log.Infof("BatchPeakPercentile: %f", r.BatchPeakPercentile)
 }
@@ -0,0 +1,182 @@
+package rec
+
+import (
+	"time"
+	"vpr/pkg/utils"
+
+	"github.com/prometheus/common/model"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	queryImages = `max by (image)(kube_pod_container_info{namespace=~"$namespace",pod=~"$podgroup$suffix",container!=""})`
+	//pod template changes (the generation also changes with the replicas scaled by an HPA): creation of the ReplicaSets of a Deployment,
+	//update revisions of a StatefulSet and generation of a DaemonSet (no replicas)
+	queryDepReplicaSets = `max by(replicaset)(kube_replicaset_created{namespace=~"$namespace"} * on(namespace,replicaset) group_left() max by(namespace,replicaset)(kube_replicaset_owner{namespace=~"$namespace",owner_kind="Deployment",owner_name="$podgroup"}))`
+	queryStsRevisions   = `max by(revision)(kube_statefulset_status_update_revision{namespace=~"$namespace",statefulset="$podgroup"})`
+	queryDsGeneration   = `max(kube_daemonset_metadata_generation{namespace=~"$namespace",daemonset="$podgroup"})`
+	//data window used for the stats of a pod group
+	dataWindowHistory  = "history"
+	dataWindowRollout  = "rollout"
+	dataWindowFallback = "history-fallback"
+)
+
+// dataWindow is the period used for the stats of a pod group
+type dataWindow struct {
+	Kind string
+	From time.Time
+	//last image or spec change (zero if none during the history)
+	Rollout time.Time
+}
+
+// usageWindow is the period of the range queries of a pod group and the exclusion windows to apply on it
+type usageWindow struct {
+	From       time.Time
+	Exclusions []int
+	//start times of each pod/container for the warm-up exclusion
+	Starts map[string][]time.Time
+}
+
+// DetectRollout finds the last image or pod template change of a pod group and restricts its data window to the period after it
+// if enough data remains, otherwise the full history is used
+func (r *Recommender) DetectRollout(podGroup PodGroup) {
+	if !r.SinceLastRollout {
+		return
+	}
+	now := time.Now()
+	window := dataWindow{Kind: dataWindowHistory, From: now.Add(-r.History)}
+	nsVars := []utils.Var{{Name: "namespace", Value: podGroup.Namespace}, {Name: "podgroup", Value: podGroup.Name}, {Name: "suffix", Value: podGroup.Suffix}}
+
+	window.Rollout = lastSeriesChange(r.queryRangeMatrix(queryImages, nsVars, window.From, now), r.Interval)
+	templateChange := time.Time{}
+	switch podGroup.Kind {
+	case dep:
+		templateChange = lastCreation(r.queryVector(queryDepReplicaSets, nsVars), window.From)
+	case sts:
+		templateChange = lastSeriesChange(r.queryRangeMatrix(queryStsRevisions, nsVars, window.From, now), r.Interval)
+	case ds:
+		for _, elem := range r.queryRangeMatrix(queryDsGeneration, nsVars, window.From, now) {
+			if change := lastValueChange(elem.Values); change.After(templateChange) {
+				templateChange = change
+			}
+		}
+	}
+	if templateChange.After(window.Rollout) {
+		window.Rollout = templateChange
+	}
+
+	if !window.Rollout.IsZero() {
+		if now.Sub(window.Rollout) >= r.MinRolloutData {
+			window.Kind = dataWindowRollout
+			window.From = window.Rollout
+		} else {
+			log.Info("Rollout of ", podGroup.Name, " at ", window.Rollout, " is too recent, full history will be used")
+			window.Kind = dataWindowFallback
+		}
+	}
+	if r.dataWindows == nil {
+		r.dataWindows = make(map[string]dataWindow)
+	}
+	r.dataWindows[podGroup.Namespace+"/"+podGroup.Name] = window
+}
+
+// dataWindowFor returns the data window of a pod group, the full history by default
+func (r *Recommender) dataWindowFor(namespace, podgroup string) dataWindow {
+	if window, ok := r.dataWindows[namespace+"/"+podgroup]; ok {
+		return window
+	}
+	return dataWindow{Kind: dataWindowHistory, From: time.Now().Add(-r.History)}
+}
+
+// usageWindowFor returns the period of the range queries and the exclusion windows of a pod group
+func (r *Recommender) usageWindowFor(namespace, podgroup string) usageWindow {
+	return usageWindow{From: r.dataWindowFor(namespace, podgroup).From, Exclusions: r.exclusionWindowsFor(namespace, podgroup)}
+}
+
+func (r *Recommender) queryRangeMatrix(query string, vars []utils.Var, start, end time.Time) model.Matrix {
+	query, err := utils.SubstVars(query, vars)
+	if err != nil {
+		log.Error("Error subst Vars:", err)
+		return nil
+	}
+	data, err := utils.PromQueryRange(r.PromURL, query, vars, start, end, r.Interval)
+	if err != nil {
+		log.Error("PromQL Range query wrong for ", query, " err ", err)
+		return nil
+	}
+	matrixVal, ok := data.(model.Matrix)
+	if !ok {
+		log.Error("Error converting to matrix for query ", query)
+		return nil
+	}
+	return matrixVal
+}
+
+func (r *Recommender) queryVector(query string, vars []utils.Var) model.Vector {
+	query, err := utils.SubstVars(query, vars)
+	if err != nil {
+		log.Error("Error subst Vars:", err)
+		return nil
+	}
+	data, err := utils.PromQuery(r.PromURL, query, vars)
+	if err != nil {
+		log.Error("PromQL Instant query wrong for ", query, " err ", err)
+		return nil
+	}
+	vectorVal, ok := data.(model.Vector)
+	if !ok {
+		log.Error("Error converting to Vector for query ", query)
+		return nil
+	}
+	return vectorVal
+}
+
+// lastSeriesChange returns the latest time a series (image, revision) (re)appeared after the start of the range
+// each series is a label value, a gap of more than 2 steps starts a new appearance (e.g. rollback)
+func lastSeriesChange(matrix model.Matrix, step time.Duration) time.Time {
+	var rangeStart model.Time
+	for _, elem := range matrix {
+		if len(elem.Values) > 0 && (rangeStart == 0 || elem.Values[0].Timestamp.Before(rangeStart)) {
+			rangeStart = elem.Values[0].Timestamp
+		}
+	}
+	var result time.Time
+	for _, elem := range matrix {
+		if len(elem.Values) == 0 {
+			continue
+		}
+		segmentStart := elem.Values[0].Timestamp
+		for i := 1; i < len(elem.Values); i++ {
+			if elem.Values[i].Timestamp.Sub(elem.Values[i-1].Timestamp) > 2*step {
+				segmentStart = elem.Values[i].Timestamp
+			}
+		}
+		//an image present since the start of the range is not a change
+		if segmentStart != rangeStart && segmentStart.Time().After(result) {
+			result = segmentStart.Time()
+		}
+	}
+	return result
+}
+
+// lastCreation returns the latest creation time (unix seconds as values) after from, zero if none
+func lastCreation(created model.Vector, from time.Time) time.Time {
+	var result time.Time
+	for _, elem := range created {
+		if creation := time.Unix(int64(elem.Value), 0); creation.After(from) && creation.After(result) {
+			result = creation
+		}
+	}
+	return result
+}
+
+// lastValueChange returns the time of the last sample whose value differs from the previous one (e.g. a new generation)
+func lastValueChange(samples []model.SamplePair) time.Time {
+	var result time.Time
+	for i := 1; i < len(samples); i++ {
+		if samples[i].Value != samples[i-1].Value {
+			result = samples[i].Timestamp.Time()
+		}
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+package rec
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// genSeries generates a sample per minute over [from, to) minutes after start
+func genSeries(start time.Time, from, to int, value float64) []model.SamplePair {
+	samples := []model.SamplePair{}
+	for i := from; i < to; i++ {
+		samples = append(samples, model.SamplePair{Timestamp: model.TimeFromUnixNano(start.Add(time.Duration(i) * time.Minute).UnixNano()), Value: model.SampleValue(value)})
+	}
+	return samples
+}
+
+func TestLastSeriesChange(t *testing.T) {
+	start := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		matrix   model.Matrix
+		expected time.Time
+	}{
+		{
+			name:     "Single image over the history",
+			matrix:   model.Matrix{{Values: genSeries(start, 0, 100, 1)}},
+			expected: time.Time{},
+		},
+		{
+			name: "New image rolled out",
+			matrix: model.Matrix{
+				{Values: genSeries(start, 0, 62, 1)},
+				{Values: genSeries(start, 60, 100, 1)},
+			},
+			expected: start.Add(60 * time.Minute),
+		},
+		{
+			name: "Rollback to the previous image",
+			matrix: model.Matrix{
+				{Values: append(genSeries(start, 0, 40, 1), genSeries(start, 70, 100, 1)...)},
+				{Values: genSeries(start, 40, 70, 1)},
+			},
+			expected: start.Add(70 * time.Minute),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := lastSeriesChange(tt.matrix, time.Minute)
+			if !result.Equal(tt.expected) {
+				t.Errorf("lastSeriesChange() = %v; want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLastValueChange(t *testing.T) {
+	start := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	samples := append(genSeries(start, 0, 30, 3), genSeries(start, 30, 50, 4)...)
+	samples = append(samples, genSeries(start, 50, 100, 5)...)
+
+	if result := lastValueChange(samples); !result.Equal(start.Add(50 * time.Minute)) {
+		t.Errorf("lastValueChange() = %v; want %v", result, start.Add(50*time.Minute))
+	}
+	if result := lastValueChange(genSeries(start, 0, 100, 3)); !result.IsZero() {
+		t.Errorf("lastValueChange() = %v; want no change", result)
+	}
+}
+
+func TestLastCreation(t *testing.T) {
+	from := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	created := model.Vector{
+		{Metric: model.Metric{"replicaset": "api-5d4f8"}, Value: model.SampleValue(from.Add(-48 * time.Hour).Unix())},
+		{Metric: model.Metric{"replicaset": "api-7c9b2"}, Value: model.SampleValue(from.Add(30 * time.Hour).Unix())},
+		{Metric: model.Metric{"replicaset": "api-6a1e3"}, Value: model.SampleValue(from.Add(6 * time.Hour).Unix())},
+	}
+	if result := lastCreation(created, from); !result.Equal(from.Add(30 * time.Hour)) {
+		t.Errorf("lastCreation() = %v; want %v", result, from.Add(30*time.Hour))
+	}
+	//a ReplicaSet created before the history (e.g. scaled by an HPA since) is not a rollout
+	if result := lastCreation(created[:1], from); !result.IsZero() {
+		t.Errorf("lastCreation() = %v; want no rollout", result)
+	}
+}
@@ -0,0 +1,148 @@
+package rec
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"vpr/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	//range, memory managed by the runtime
+	queryGoManagedMemory     = `sum by(pod,container)(go_memstats_heap_inuse_bytes{pod=~"$podgroup$suffix"} + go_memstats_stack_inuse_bytes{pod=~"$podgroup$suffix"}) / 1048576`
+	queryNodeOldSpaceMemory  = `sum by(pod,container)(nodejs_heap_space_size_used_bytes{pod=~"$podgroup$suffix",space="old"}) / 1048576`
+	runtimeGo                = "go"
+	runtimeNode              = "nodejs"
+	runtimeKnobGoMemLimit    = "GOMEMLIMIT"
+	runtimeKnobNodeOptions   = "NODE_OPTIONS"
+	runtimeKnobNodeOldSpaceF = "--max-old-space-size="
+)
+
+// RuntimeUsage is the memory managed by a runtime (Go, Node.js...) in a container
+type RuntimeUsage struct {
+	Runtime string
+	//max over the data window of the memory the runtime knob bounds (Go heap and stacks, V8 old space)
+	ManagedMB float64
+}
+
+// runtimePlugin detects a runtime in the containers of a pod group and sizes its memory knob with the container limit
+type runtimePlugin interface {
+	name() string
+	//max managed memory per container, empty if the pod group does not run this runtime
+	usage(r *Recommender, vars []utils.Var, window usageWindow) map[string]RuntimeUsage
+	//min container limit keeping the managed memory under the knob
+	minLimitMB(r *Recommender, usage RuntimeUsage) float64
+	//environment variable and value of the knob for the container limit, set in the current value of the variable
+	knob(r *Recommender, limitMB float64, current string) (string, string)
+}
+
+// runtimePlugins are the supported runtimes in detection order (the JVM has its own sizing)
+var runtimePlugins = []runtimePlugin{goRuntime{}, nodeRuntime{}}
+
+// GetPodGroupRuntimeUsage get the runtime managed memory of the containers of a pod group (first runtime detected per container)
+func (r *Recommender) GetPodGroupRuntimeUsage(namespace, podgroup, suffixKind string) map[string]RuntimeUsage {
+	result := make(map[string]RuntimeUsage)
+	nsVars := []utils.Var{{Name: "namespace", Value: namespace}, {Name: "podgroup", Value: podgroup}, {Name: "suffix", Value: suffixKind}, {Name: "interval", Value: r.Interval.String()}}
+	window := r.usageWindowFor(namespace, podgroup)
+	for _, plugin := range runtimePlugins {
+		for container, usage := range plugin.usage(r, nsVars, window) {
+			if _, ok := result[container]; !ok {
+				log.Info("Runtime ", usage.Runtime, " detected for pod group ", podgroup, " container ", container)
+				result[container] = usage
+			}
+		}
+	}
+	return result
+}
+
+// runtimePluginNamed returns the plugin of a runtime, nil if unknown
+func runtimePluginNamed(name string) runtimePlugin {
+	for _, plugin := range runtimePlugins {
+		if plugin.name() == name {
+			return plugin
+		}
+	}
+	return nil
+}
+
+// managedUsage returns the max of a managed memory query per container
+func (r *Recommender) managedUsage(runtime, query string, vars []utils.Var, window usageWindow) map[string]RuntimeUsage {
+	result := make(map[string]RuntimeUsage)
+	for _, elem := range r.getPodContainerJVMHistoryUsage(runtime, query, vars, window) {
+		if elem.Values.Max > 0 {
+			result[elem.Name] = RuntimeUsage{Runtime: runtime, ManagedMB: elem.Values.Max}
+		}
+	}
+	return result
+}
+
+// goRuntime sizes GOMEMLIMIT (soft limit of the Go managed memory) at GoMemLimitPercent of the container limit
+type goRuntime struct{}
+
+func (goRuntime) name() string { return runtimeGo }
+
+func (goRuntime) usage(r *Recommender, vars []utils.Var, window usageWindow) map[string]RuntimeUsage {
+	return r.managedUsage(runtimeGo, queryGoManagedMemory, vars, window)
+}
+
+func (goRuntime) minLimitMB(r *Recommender, usage RuntimeUsage) float64 {
+	return usage.ManagedMB * 100.0 / r.GoMemLimitPercent
+}
+
+func (goRuntime) knob(r *Recommender, limitMB float64, _ string) (string, string) {
+	return runtimeKnobGoMemLimit, strconv.FormatFloat(math.Floor(limitMB*r.GoMemLimitPercent/100.0), 'f', 0, 64) + "MiB"
+}
+
+// nodeRuntime sizes --max-old-space-size (V8 old space) at NodeOldSpacePercent of the container limit
+type nodeRuntime struct{}
+
+func (nodeRuntime) name() string { return runtimeNode }
+
+func (nodeRuntime) usage(r *Recommender, vars []utils.Var, window usageWindow) map[string]RuntimeUsage {
+	return r.managedUsage(runtimeNode, queryNodeOldSpaceMemory, vars, window)
+}
+
+func (nodeRuntime) minLimitMB(r *Recommender, usage RuntimeUsage) float64 {
+	return usage.ManagedMB * 100.0 / r.NodeOldSpacePercent
+}
+
+// the other Node.js options are kept
+func (nodeRuntime) knob(r *Recommender, limitMB float64, current string) (string, string) {
+	options := []string{}
+	for _, option := range strings.Fields(current) {
+		if !strings.HasPrefix(option, runtimeKnobNodeOldSpaceF) {
+			options = append(options, option)
+		}
+	}
+	options = append(options, runtimeKnobNodeOldSpaceF+strconv.FormatFloat(math.Floor(limitMB*r.NodeOldSpacePercent/100.0), 'f', 0, 64))
+	return runtimeKnobNodeOptions, strings.Join(options, " ")
+}
+
+// runtimeMinLimitMB returns the min container limit required by the runtime of a container (0 if no runtime detected)
+func (r *Recommender) runtimeMinLimitMB(usage RuntimeUsage) float64 {
+	plugin := runtimePluginNamed(usage.Runtime)
+	if plugin == nil {
+		return 0
+	}
+	return plugin.minLimitMB(r, usage)
+}
+
+// applyRuntimeKnob sets the runtime memory knob matching the final memory limit in the current options of the container
+func (r *Recommender) applyRuntimeKnob(c *Recommendation, usage RuntimeUsage, currentOptions string) {
+	plugin := runtimePluginNamed(usage.Runtime)
+	if plugin == nil || c.NewMemLimitMB <= 0 {
+		return
+	}
+	c.Runtime = usage.Runtime
+	c.RuntimeKnobName, c.RuntimeKnobValue = plugin.knob(r, c.NewMemLimitMB, currentOptions)
+}
+
+// runtimeKnob returns the knob as NAME=value (empty if none)
+func runtimeKnob(name, value string) string {
+	if name == "" {
+		return ""
+	}
+	return name + "=" + value
+}
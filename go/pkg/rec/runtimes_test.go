@@ -0,0 +1,66 @@
+package rec
+
+import (
+	"testing"
+)
+
+func TestApplyRuntimeKnob(t *testing.T) {
+	tests := []struct {
+		name      string
+		usage     RuntimeUsage
+		limitMB   float64
+		current   string
+		minLimit  float64
+		runtime   string
+		knob      string
+		knobValue string
+	}{
+		{
+			name:      "Go GOMEMLIMIT at 90% of the limit",
+			usage:     RuntimeUsage{Runtime: runtimeGo, ManagedMB: 450},
+			limitMB:   1000,
+			minLimit:  500,
+			runtime:   runtimeGo,
+			knob:      "GOMEMLIMIT=900MiB",
+			knobValue: "900MiB",
+		},
+		{
+			name:      "Node.js old space at 75% of the limit",
+			usage:     RuntimeUsage{Runtime: runtimeNode, ManagedMB: 600},
+			limitMB:   1000,
+			minLimit:  800,
+			runtime:   runtimeNode,
+			knob:      "NODE_OPTIONS=--max-old-space-size=750",
+			knobValue: "--max-old-space-size=750",
+		},
+		{
+			name:      "Node.js old space set in the current options",
+			usage:     RuntimeUsage{Runtime: runtimeNode, ManagedMB: 600},
+			limitMB:   1000,
+			current:   "--max-old-space-size=2048 --enable-source-maps",
+			minLimit:  800,
+			runtime:   runtimeNode,
+			knob:      "NODE_OPTIONS=--enable-source-maps --max-old-space-size=750",
+			knobValue: "--enable-source-maps --max-old-space-size=750",
+		},
+		{
+			name:    "No runtime detected",
+			usage:   RuntimeUsage{},
+			limitMB: 1000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Recommender{GoMemLimitPercent: 90, NodeOldSpacePercent: 75}
+			if minLimit := r.runtimeMinLimitMB(tt.usage); minLimit != tt.minLimit {
+				t.Errorf("runtimeMinLimitMB() = %v; want %v", minLimit, tt.minLimit)
+			}
+			c := Recommendation{NewMemLimitMB: tt.limitMB}
+			r.applyRuntimeKnob(&c, tt.usage, tt.current)
+			if c.Runtime != tt.runtime || c.RuntimeKnobValue != tt.knobValue || runtimeKnob(c.RuntimeKnobName, c.RuntimeKnobValue) != tt.knob {
+				t.Errorf("applyRuntimeKnob() = %q %q %q; want %q %q %q", c.Runtime, c.RuntimeKnobName, c.RuntimeKnobValue, tt.runtime, tt.knob, tt.knobValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package rec
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/montanaflynn/stats"
+	"github.com/prometheus/common/model"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	profileFlat   = "flat"
+	profileDaily  = "daily"
+	profileWeekly = "weekly"
+	//a bucket needs enough samples for its percentile to be meaningful
+	minSamplesPerBucket = 10
+	//a day of week bucket covering a single week is one contiguous hour, any trend would look weekly
+	minWeeksPerBucket = 2
+)
+
+// SeasonalProfile is the periodicity detected on a usage history
+type SeasonalProfile struct {
+	//flat, daily, weekly or daily+weekly
+	Profile string
+	//bucket with the highest percentile e.g. "14h" or "Monday 14h" for a weekly profile
+	PeakBucket     string
+	PeakPercentile float64
+}
+
+// Periodic returns true if a daily or weekly periodicity was detected
+func (p SeasonalProfile) Periodic() bool {
+	return p.Profile != "" && p.Profile != profileFlat
+}
+
+// seasonalProfile buckets the samples by day of week and hour of day (7x24 buckets),
+// detects daily (hour of day) and weekly (day of week) periodicity
+// from the share of the variance explained by the buckets and returns the percentile of the peak bucket
+// the weekly periodicity is only detected when each bucket covers minWeeksPerBucket weeks (e.g. 14 days of history)
+// and is cross-validated across the weeks so that a trend or a random walk is not seen as weekly
+// the peak bucket is the hour of day unless the profile is weekly
+func seasonalProfile(samples []model.SamplePair, percent, minStrength float64, location *time.Location) SeasonalProfile {
+	result := SeasonalProfile{Profile: profileFlat}
+	if len(samples) == 0 {
+		return result
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	all := make([]float64, len(samples))
+	buckets := make([]string, len(samples))
+	weeks := make([]int, len(samples))
+	byHour := make(map[string][]float64)
+	byBucket := make(map[string][]float64)
+	weeksByBucket := make(map[string]map[int]bool)
+	for i, sample := range samples {
+		value := float64(sample.Value)
+		all[i] = value
+		t := sample.Timestamp.Time().In(location)
+		hour := strconv.Itoa(t.Hour()) + "h"
+		byHour[hour] = append(byHour[hour], value)
+		bucket := t.Weekday().String() + " " + hour
+		byBucket[bucket] = append(byBucket[bucket], value)
+		if weeksByBucket[bucket] == nil {
+			weeksByBucket[bucket] = make(map[int]bool)
+		}
+		year, week := t.ISOWeek()
+		buckets[i], weeks[i] = bucket, year*100+week
+		weeksByBucket[bucket][weeks[i]] = true
+	}
+	weeklyCovered := len(byBucket) == 7*24
+	for _, weeks := range weeksByBucket {
+		if len(weeks) < minWeeksPerBucket {
+			weeklyCovered = false
+		}
+	}
+
+	//daily strength is the variance explained by the hour of day
+	//weekly strength is the extra variance explained when splitting each hour by day of week (predicted from the other weeks)
+	profiles := []string{}
+	dailyStrength := explainedVariance(all, byHour)
+	if dailyStrength >= minStrength {
+		profiles = append(profiles, profileDaily)
+	}
+	peakBuckets := byHour
+	if weeklyCovered && crossValidatedVariance(all, buckets, weeks)-dailyStrength >= minStrength {
+		profiles = append(profiles, profileWeekly)
+		peakBuckets = byBucket
+	}
+	if len(profiles) > 0 {
+		result.Profile = strings.Join(profiles, "+")
+	}
+
+	for bucket, values := range peakBuckets {
+		if len(values) < minSamplesPerBucket {
+			continue
+		}
+		percentile, err := stats.Percentile(values, percent)
+		if err != nil {
+			log.Error("Error getting Percentile for bucket ", bucket, " err ", err)
+			continue
+		}
+		//ties are broken on the bucket name to stay deterministic
+		if percentile > result.PeakPercentile || (percentile == result.PeakPercentile && bucket < result.PeakBucket) {
+			result.PeakPercentile = percentile
+			result.PeakBucket = bucket
+		}
+	}
+	return result
+}
+
+// explainedVariance returns the share (0 to 1) of the variance of values explained by the groups means
+func explainedVariance(values []float64, groups map[string][]float64) float64 {
+	mean, _ := stats.Mean(values)
+	total := 0.0
+	for _, value := range values {
+		total += (value - mean) * (value - mean)
+	}
+	if total == 0 {
+		return 0
+	}
+	between := 0.0
+	for _, group := range groups {
+		groupMean, _ := stats.Mean(group)
+		between += float64(len(group)) * (groupMean - mean) * (groupMean - mean)
+	}
+	return between / total
+}
+
+// crossValidatedVariance returns the share of the variance of values explained by the mean of their bucket in the other weeks
+// (negative when the buckets do not repeat from a week to the other), each bucket must cover several weeks
+func crossValidatedVariance(values []float64, buckets []string, weeks []int) float64 {
+	type cell struct {
+		sum   float64
+		count float64
+	}
+	byBucket := make(map[string]*cell)
+	byBucketWeek := make(map[string]*cell)
+	for i, value := range values {
+		cellKey := buckets[i] + "/" + strconv.Itoa(weeks[i])
+		if byBucket[buckets[i]] == nil {
+			byBucket[buckets[i]] = &cell{}
+		}
+		if byBucketWeek[cellKey] == nil {
+			byBucketWeek[cellKey] = &cell{}
+		}
+		byBucket[buckets[i]].sum += value
+		byBucket[buckets[i]].count++
+		byBucketWeek[cellKey].sum += value
+		byBucketWeek[cellKey].count++
+	}
+	mean, _ := stats.Mean(values)
+	total, residual := 0.0, 0.0
+	for i, value := range values {
+		bucket, week := byBucket[buckets[i]], byBucketWeek[buckets[i]+"/"+strconv.Itoa(weeks[i])]
+		if bucket.count == week.count {
+			return 0
+		}
+		predicted := (bucket.sum - week.sum) / (bucket.count - week.count)
+		total += (value - mean) * (value - mean)
+		residual += (value - predicted) * (value - predicted)
+	}
+	if total == 0 {
+		return 0
+	}
+	return 1 - residual/total
+}
@@ -0,0 +1,112 @@
+package rec
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// genWeekSamples generates weeks of samples every 5 minutes starting on a Monday
+func genWeekSamples(weeks int, value func(t time.Time) float64) []model.SamplePair {
+	samples := []model.SamplePair{}
+	start := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	for t := start; t.Before(start.Add(time.Duration(weeks) * 7 * 24 * time.Hour)); t = t.Add(5 * time.Minute) {
+		samples = append(samples, model.SamplePair{Timestamp: model.TimeFromUnixNano(t.UnixNano()), Value: model.SampleValue(value(t))})
+	}
+	return samples
+}
+
+func TestSeasonalProfile(t *testing.T) {
+	tests := []struct {
+		name         string
+		value        func(t time.Time) float64
+		expected     string
+		expectedPeak float64
+	}{
+		{
+			name:         "Flat usage",
+			value:        func(t time.Time) float64 { return 100 + float64(t.Minute()%3) },
+			expected:     profileFlat,
+			expectedPeak: 102,
+		},
+		{
+			name: "Market hours on weekdays",
+			value: func(t time.Time) float64 {
+				if t.Weekday() != time.Saturday && t.Weekday() != time.Sunday && t.Hour() >= 9 && t.Hour() < 17 {
+					return 1000
+				}
+				return 100
+			},
+			expected:     profileDaily + "+" + profileWeekly,
+			expectedPeak: 1000,
+		},
+		{
+			name: "Idle on weekends",
+			value: func(t time.Time) float64 {
+				if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+					return 100
+				}
+				return 600
+			},
+			expected:     profileWeekly,
+			expectedPeak: 600,
+		},
+		{
+			name: "Nightly batch every day",
+			value: func(t time.Time) float64 {
+				if t.Hour() == 2 {
+					return 800
+				}
+				return 100
+			},
+			expected:     profileDaily,
+			expectedPeak: 800,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := seasonalProfile(genWeekSamples(2, tt.value), 90, 0.3, time.UTC)
+			if result.Profile != tt.expected || result.PeakPercentile != tt.expectedPeak {
+				t.Errorf("seasonalProfile() = %+v; want profile %v peak %v", result, tt.expected, tt.expectedPeak)
+			}
+		})
+	}
+}
+
+func TestSeasonalProfileDayOfWeek(t *testing.T) {
+	//weekly report on Monday mornings only
+	mondayReport := func(t time.Time) float64 {
+		if t.Weekday() == time.Monday && t.Hour() == 8 {
+			return 900
+		}
+		return 100
+	}
+	result := seasonalProfile(genWeekSamples(2, mondayReport), 90, 0.3, time.UTC)
+	if result.PeakBucket != "Monday 8h" || result.PeakPercentile != 900 {
+		t.Errorf("seasonalProfile() = %+v; want peak Monday 8h at 900", result)
+	}
+
+	//a single week does not cover each day of week bucket twice, only the hour of day is used
+	result = seasonalProfile(genWeekSamples(1, mondayReport), 90, 0.3, time.UTC)
+	if result.Profile == profileWeekly || result.PeakBucket != "8h" {
+		t.Errorf("seasonalProfile() on a week = %+v; want no weekly profile and peak 8h", result)
+	}
+}
+
+func TestSeasonalProfileRandomWalk(t *testing.T) {
+	//a non periodic series drifting over the history must not be seen as seasonal
+	for _, weeks := range []int{1, 2} {
+		rnd := rand.New(rand.NewSource(42))
+		value := 1000.0
+		samples := genWeekSamples(weeks, func(t time.Time) float64 {
+			value += rnd.NormFloat64() * 10
+			return value
+		})
+		if result := seasonalProfile(samples, 90, 0.3, time.UTC); result.Periodic() {
+			t.Errorf("seasonalProfile() on a %d week random walk = %+v; want flat", weeks, result)
+		}
+	}
+}
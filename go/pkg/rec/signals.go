@@ -0,0 +1,115 @@
+package rec
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"vpr/pkg/utils"
+
+	"github.com/prometheus/common/model"
+)
+
+const (
+	//match of a JVM signal result to a container
+	jvmSignalMatchContainer = "container"
+	jvmSignalMatchContains  = "contains"
+	jvmSignalMatchAll       = "all"
+	//effect of a fired JVM signal on the heap
+	jvmSignalReport   = "report"
+	jvmSignalNoShrink = "no-shrink"
+	jvmSignalGrow     = "grow"
+	//signal kept in the JVMAllocationStalls column
+	jvmSignalAllocationStall = "allocation_stall"
+)
+
+// JVMSignalValue is the value of a JVM health signal for a container
+type JVMSignalValue struct {
+	Name  string
+	Value float64
+}
+
+// getJVMSignals returns the values of the JVM health signals for each container (summed when several results match)
+func (r *Recommender) getJVMSignals(vars []utils.Var, containers []string) map[string][]JVMSignalValue {
+	result := make(map[string][]JVMSignalValue)
+	for _, signal := range r.JVMSignals {
+		values := make(map[string]float64)
+		for _, elem := range r.queryVector(signal.Query, vars) {
+			label := string(elem.Metric[model.LabelName(signal.MatchLabel)])
+			for _, container := range containers {
+				if signalMatches(signal.MatchMode, label, container) {
+					values[container] += float64(elem.Value)
+				}
+			}
+		}
+		for container, value := range values {
+			result[container] = append(result[container], JVMSignalValue{Name: signal.Name, Value: value})
+		}
+	}
+	return result
+}
+
+// signalMatches returns true if the label value of a signal result identifies the container
+func signalMatches(mode, label, container string) bool {
+	switch mode {
+	case jvmSignalMatchContainer:
+		return label == container
+	case jvmSignalMatchContains:
+		return strings.Contains(label, container)
+	case jvmSignalMatchAll:
+		return true
+	}
+	return false
+}
+
+// firedJVMSignals returns the signals above their threshold
+func (r *Recommender) firedJVMSignals(values []JVMSignalValue) []JVMSignalValue {
+	result := []JVMSignalValue{}
+	for _, value := range values {
+		for _, signal := range r.JVMSignals {
+			if signal.Name == value.Name && value.Value > signal.Threshold {
+				result = append(result, value)
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// signalsMinXmx returns the min heap required by the effects of the fired signals (0 if none)
+func (r *Recommender) signalsMinXmx(fired []JVMSignalValue, currentXmxMB float64) float64 {
+	minXmx := 0.0
+	for _, value := range fired {
+		for _, signal := range r.JVMSignals {
+			if signal.Name != value.Name {
+				continue
+			}
+			switch signal.Effect {
+			case jvmSignalNoShrink:
+				minXmx = math.Max(minXmx, currentXmxMB)
+			case jvmSignalGrow:
+				minXmx = math.Max(minXmx, currentXmxMB*(100.0+signal.EffectValue)/100.0)
+			}
+		}
+	}
+	return minXmx
+}
+
+// formatJVMSignals returns the signals as name=value separated by |
+func formatJVMSignals(values []JVMSignalValue) string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		result = append(result, value.Name+"="+strconv.FormatFloat(value.Value, 'f', -1, 64))
+	}
+	return strings.Join(result, "|")
+}
+
+// allocationStalls returns the value of the allocation stall signal (0 if not configured)
+func allocationStalls(values []JVMSignalValue) int {
+	for _, value := range values {
+		if value.Name == jvmSignalAllocationStall {
+			return int(value.Value)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,65 @@
+package rec
+
+import (
+	"testing"
+	"vpr/pkg/utils"
+)
+
+func TestSignalMatches(t *testing.T) {
+	tests := []struct {
+		mode      string
+		label     string
+		container string
+		expected  bool
+	}{
+		{jvmSignalMatchContainer, "api", "api", true},
+		{jvmSignalMatchContainer, "api-sidecar", "api", false},
+		{jvmSignalMatchContains, "shop-api", "api", true},
+		{jvmSignalMatchContains, "shop-web", "api", false},
+		{jvmSignalMatchAll, "", "api", true},
+		{"unknown", "api", "api", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode+"/"+tt.label, func(t *testing.T) {
+			if result := signalMatches(tt.mode, tt.label, tt.container); result != tt.expected {
+				t.Errorf("signalMatches(%s, %s, %s) = %t; want %t", tt.mode, tt.label, tt.container, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestJVMSignalsEffects(t *testing.T) {
+	r := &Recommender{JVMSignals: []utils.JVMSignal{
+		{Name: jvmSignalAllocationStall, Threshold: 0, Effect: jvmSignalReport},
+		{Name: "humongous_allocations", Threshold: 100, Effect: jvmSignalNoShrink},
+		{Name: "oom_errors", Threshold: 0, Effect: jvmSignalGrow, EffectValue: 25},
+	}}
+	tests := []struct {
+		name      string
+		values    []JVMSignalValue
+		formatted string
+		minXmx    float64
+		stalls    int
+	}{
+		{"below thresholds", []JVMSignalValue{{"humongous_allocations", 50}, {"oom_errors", 0}}, "", 0, 0},
+		{"report only", []JVMSignalValue{{jvmSignalAllocationStall, 3}}, "allocation_stall=3", 0, 3},
+		{"no shrink", []JVMSignalValue{{"humongous_allocations", 150}}, "humongous_allocations=150", 1000, 0},
+		{"grow wins", []JVMSignalValue{{"oom_errors", 2}, {"humongous_allocations", 150}}, "humongous_allocations=150|oom_errors=2", 1250, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fired := r.firedJVMSignals(tt.values)
+			if formatted := formatJVMSignals(fired); formatted != tt.formatted {
+				t.Errorf("formatJVMSignals() = %s; want %s", formatted, tt.formatted)
+			}
+			if minXmx := r.signalsMinXmx(fired, 1000); minXmx != tt.minXmx {
+				t.Errorf("signalsMinXmx() = %v; want %v", minXmx, tt.minXmx)
+			}
+			if stalls := allocationStalls(tt.values); stalls != tt.stalls {
+				t.Errorf("allocationStalls() = %v; want %v", stalls, tt.stalls)
+			}
+		})
+	}
+}
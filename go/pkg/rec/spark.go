@@ -0,0 +1,197 @@
+package rec
+
+import (
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"vpr/pkg/types"
+	"vpr/pkg/utils"
+
+	"github.com/prometheus/common/model"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// OutPathCsvSparkRecommendations is the path to the CSV file with the Spark conf recommendations per application
+	OutPathCsvSparkRecommendations = types.DataPath + "spark_recommendations.csv"
+	//Spark pods with their application labels (the labels must be allowed in kube-state-metrics --metric-labels-allowlist)
+	querySparkPods = `max by(namespace,pod,label_spark_role,label_spark_app_selector,label_spark_app_name,label_sparkoperator_k8s_io_app_name)(kube_pod_labels{namespace=~"$namespace",label_spark_app_selector!=""})`
+	//containers of the Spark driver and executors
+	sparkDriverContainer   = "spark-kubernetes-driver"
+	sparkExecutorContainer = "spark-kubernetes-executor"
+	sparkRoleDriver        = "driver"
+	sparkRoleExecutor      = "executor"
+	//min memory overhead enforced by Spark on Kubernetes
+	sparkMinMemoryOverheadMB = 384.0
+)
+
+var sparkExecutorPod = regexp.MustCompile(`^(.*)-\w+-exec-\d+$`)
+
+// SparkApps maps the Spark driver and executor pod groups to their application
+type SparkApps map[string]string
+
+// sparkConf is the recommended Spark conf of a role (driver or executor) of an application
+type sparkConf struct {
+	MemoryMB         float64
+	MemoryOverheadMB float64
+	RequestCoresM    float64
+}
+
+// GetSparkApps get the application of the Spark driver and executor pod groups from the spark-app-selector label
+func (r *Recommender) GetSparkApps() SparkApps {
+	nsVars := []utils.Var{{Name: "namespace", Value: r.Namespace}}
+	apps := sparkAppsFrom(r.queryVector(querySparkPods, nsVars))
+	log.Info("Found ", len(apps), " Spark pod groups with an application")
+	return apps
+}
+
+// sparkAppsFrom maps the pod groups to the application name of their pods
+// the Spark Operator SparkApplication name wins over the spark-app-name label which wins over the spark-app-selector (one per run)
+func sparkAppsFrom(pods model.Vector) SparkApps {
+	apps := make(SparkApps)
+	for _, elem := range pods {
+		app := string(elem.Metric["label_spark_app_selector"])
+		if name := string(elem.Metric["label_spark_app_name"]); name != "" {
+			app = name
+		}
+		if name := string(elem.Metric["label_sparkoperator_k8s_io_app_name"]); name != "" {
+			app = name
+		}
+		namespace, pod := string(elem.Metric["namespace"]), string(elem.Metric["pod"])
+		switch string(elem.Metric["label_spark_role"]) {
+		case sparkRoleDriver:
+			apps[sparkAppKey(sparkDrivers, namespace, pod)] = app
+		case sparkRoleExecutor:
+			if matches := sparkExecutorPod.FindStringSubmatch(pod); len(matches) > 1 {
+				apps[sparkAppKey(sparkExecutors, namespace, matches[1])] = app
+			}
+		}
+	}
+	return apps
+}
+
+func sparkAppKey(kind, namespace, podgroup string) string {
+	return kind + "/" + namespace + "/" + podgroup
+}
+
+// AppOf returns the Spark application of a pod group (empty if not a Spark pod group)
+func (apps SparkApps) AppOf(podGroup PodGroup) string {
+	return apps[sparkAppKey(podGroup.Kind, podGroup.Namespace, podGroup.Name)]
+}
+
+// sparkRoleOf returns the Spark role of a recommendation (empty for the other containers, e.g. sidecars)
+func sparkRoleOf(c Recommendation) string {
+	switch {
+	case c.Kind == sparkDrivers && c.ContainerName == sparkDriverContainer:
+		return sparkRoleDriver
+	case c.Kind == sparkExecutors && c.ContainerName == sparkExecutorContainer:
+		return sparkRoleExecutor
+	}
+	return ""
+}
+
+// sparkConfFor returns the Spark conf giving the recommended memory limit to the pod
+// the heap is the new Xmx from the JVM stats (limit / (1 + overhead factor) without them) and the overhead is the rest of the limit, at least the off-heap budget
+func (r *Recommender) sparkConfFor(c Recommendation) sparkConf {
+	memory := c.NewMemLimitMB / (1.0 + r.SparkMemoryOverheadFactor)
+	if c.NewJVMXmxMB > 0 {
+		memory = c.NewJVMXmxMB
+	}
+	memory = math.Ceil(memory)
+	overhead := math.Max(math.Ceil(c.NewMemLimitMB-memory), math.Ceil(c.OffHeapBudgetMB))
+	return sparkConf{
+		MemoryMB:         memory,
+		MemoryOverheadMB: math.Max(overhead, sparkMinMemoryOverheadMB),
+		RequestCoresM:    math.Ceil(c.NewCPUReqM),
+	}
+}
+
+// sparkConfValues returns the spark.* conf values of a role
+func sparkConfValues(role string, conf sparkConf) map[string]string {
+	return map[string]string{
+		"spark." + role + ".memory":                   strconv.FormatFloat(conf.MemoryMB, 'f', 0, 64) + "m",
+		"spark." + role + ".memoryOverhead":           strconv.FormatFloat(conf.MemoryOverheadMB, 'f', 0, 64) + "m",
+		"spark.kubernetes." + role + ".request.cores": strconv.FormatFloat(conf.RequestCoresM, 'f', 0, 64) + "m",
+	}
+}
+
+// genSparkConfs returns the recommended Spark conf per application (namespace/app) and role, the max over the pod groups of a role
+func (r *Recommender) genSparkConfs(recs []Recommendation) map[string]map[string]sparkConf {
+	result := make(map[string]map[string]sparkConf)
+	for _, c := range recs {
+		role := sparkRoleOf(c)
+		if c.SparkApp == "" || role == "" || c.NewMemLimitMB <= 0 {
+			continue
+		}
+		key := c.Namespace + "/" + c.SparkApp
+		if _, ok := result[key]; !ok {
+			result[key] = make(map[string]sparkConf)
+		}
+		conf := r.sparkConfFor(c)
+		previous := result[key][role]
+		result[key][role] = sparkConf{
+			MemoryMB:         math.Max(previous.MemoryMB, conf.MemoryMB),
+			MemoryOverheadMB: math.Max(previous.MemoryOverheadMB, conf.MemoryOverheadMB),
+			RequestCoresM:    math.Max(previous.RequestCoresM, conf.RequestCoresM),
+		}
+	}
+	return result
+}
+
+// genSparkConfSnippet returns the sparkConf of a SparkApplication spec (Spark Operator) with the recommended values
+func genSparkConfSnippet(app string, confs map[string]sparkConf) string {
+	values := make(map[string]string)
+	for role, conf := range confs {
+		for name, value := range sparkConfValues(role, conf) {
+			values[name] = value
+		}
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	sb.WriteString("# VPR Spark recommendations\n")
+	sb.WriteString("# " + app + "\n")
+	sb.WriteString("spec:\n")
+	sb.WriteString("  sparkConf:\n")
+	for _, name := range names {
+		sb.WriteString("    \"" + name + "\": \"" + values[name] + "\"\n")
+	}
+	return sb.String()
+}
+
+// GenSparkRecommendations writes the recommended Spark conf per application as a CSV file and a sparkConf snippet per application
+func (r *Recommender) GenSparkRecommendations(recs []Recommendation) {
+	confs := r.genSparkConfs(recs)
+	if len(confs) == 0 {
+		return
+	}
+	apps := make([]string, 0, len(confs))
+	for app := range confs {
+		apps = append(apps, app)
+	}
+	sort.Strings(apps)
+	csvData := [][]string{{"Namespace", "SparkApp", "Role", "MemoryMB", "MemoryOverheadMB", "RequestCoresM"}}
+	for _, app := range apps {
+		namespaceApp := strings.SplitN(app, "/", 2)
+		for _, role := range []string{sparkRoleDriver, sparkRoleExecutor} {
+			if conf, ok := confs[app][role]; ok {
+				csvData = append(csvData, []string{namespaceApp[0], namespaceApp[1], role,
+					strconv.FormatFloat(conf.MemoryMB, 'f', 0, 64),
+					strconv.FormatFloat(conf.MemoryOverheadMB, 'f', 0, 64),
+					strconv.FormatFloat(conf.RequestCoresM, 'f', 0, 64)})
+			}
+		}
+		path := types.DataPath + "spark-" + namespaceApp[0] + "-" + namespaceApp[1] + ".yaml"
+		if err := os.WriteFile(path, []byte(genSparkConfSnippet(app, confs[app])), 0644); err != nil {
+			log.Error("Error writing Spark conf file ", path, " err ", err)
+		}
+	}
+	utils.GenCSV(OutPathCsvSparkRecommendations, csvData)
+	log.Info("Generated Spark recommendations for ", len(apps), " applications")
+}
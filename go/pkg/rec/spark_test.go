@@ -0,0 +1,87 @@
+package rec
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestSparkAppsFrom(t *testing.T) {
+	pods := model.Vector{
+		{Metric: model.Metric{"namespace": "etl", "pod": "daily-report-driver", "label_spark_role": "driver", "label_spark_app_selector": "spark-1a2b", "label_sparkoperator_k8s_io_app_name": "daily-report"}},
+		{Metric: model.Metric{"namespace": "etl", "pod": "daily-report-3f4e5d6c-exec-2", "label_spark_role": "executor", "label_spark_app_selector": "spark-1a2b", "label_sparkoperator_k8s_io_app_name": "daily-report"}},
+		{Metric: model.Metric{"namespace": "etl", "pod": "adhoc-driver", "label_spark_role": "driver", "label_spark_app_selector": "spark-9z8y"}},
+	}
+	apps := sparkAppsFrom(pods)
+	tests := []struct {
+		podGroup PodGroup
+		app      string
+	}{
+		{PodGroup{Kind: sparkDrivers, Namespace: "etl", Name: "daily-report-driver"}, "daily-report"},
+		{PodGroup{Kind: sparkExecutors, Namespace: "etl", Name: "daily-report"}, "daily-report"},
+		{PodGroup{Kind: sparkDrivers, Namespace: "etl", Name: "adhoc-driver"}, "spark-9z8y"},
+		{PodGroup{Kind: dep, Namespace: "etl", Name: "daily-report"}, ""},
+	}
+	for _, tt := range tests {
+		if app := apps.AppOf(tt.podGroup); app != tt.app {
+			t.Errorf("AppOf(%v) = %q; want %q", tt.podGroup, app, tt.app)
+		}
+	}
+}
+
+func TestSparkConfFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    Recommendation
+		expected sparkConf
+	}{
+		{
+			name:     "Heap from the JVM stats, overhead is the rest of the limit",
+			input:    Recommendation{NewMemLimitMB: 4096, NewJVMXmxMB: 3000, NewCPUReqM: 1500},
+			expected: sparkConf{MemoryMB: 3000, MemoryOverheadMB: 1096, RequestCoresM: 1500},
+		},
+		{
+			name:     "No JVM stats, overhead factor applied",
+			input:    Recommendation{NewMemLimitMB: 5500, NewCPUReqM: 800},
+			expected: sparkConf{MemoryMB: 5000, MemoryOverheadMB: 500, RequestCoresM: 800},
+		},
+		{
+			name:     "Overhead at least the Spark min",
+			input:    Recommendation{NewMemLimitMB: 1100, NewCPUReqM: 100},
+			expected: sparkConf{MemoryMB: 1000, MemoryOverheadMB: 384, RequestCoresM: 100},
+		},
+	}
+
+	r := &Recommender{SparkMemoryOverheadFactor: 0.1}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if conf := r.sparkConfFor(tt.input); conf != tt.expected {
+				t.Errorf("sparkConfFor() = %v; want %v", conf, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGenSparkConfSnippet(t *testing.T) {
+	r := &Recommender{SparkMemoryOverheadFactor: 0.1}
+	recs := []Recommendation{
+		{Namespace: "etl", Kind: sparkDrivers, ContainerName: sparkDriverContainer, SparkApp: "daily-report", NewMemLimitMB: 2200, NewCPUReqM: 500},
+		{Namespace: "etl", Kind: sparkExecutors, ContainerName: sparkExecutorContainer, SparkApp: "daily-report", NewMemLimitMB: 4096, NewJVMXmxMB: 3000, NewCPUReqM: 1500},
+		{Namespace: "etl", Kind: sparkExecutors, ContainerName: "istio-proxy", SparkApp: "daily-report", NewMemLimitMB: 128, NewCPUReqM: 50},
+	}
+	confs := r.genSparkConfs(recs)
+	expected := `# VPR Spark recommendations
+# etl/daily-report
+spec:
+  sparkConf:
+    "spark.driver.memory": "2000m"
+    "spark.driver.memoryOverhead": "384m"
+    "spark.executor.memory": "3000m"
+    "spark.executor.memoryOverhead": "1096m"
+    "spark.kubernetes.driver.request.cores": "500m"
+    "spark.kubernetes.executor.request.cores": "1500m"
+`
+	if snippet := genSparkConfSnippet("etl/daily-report", confs["etl/daily-report"]); snippet != expected {
+		t.Errorf("genSparkConfSnippet() = %q; want %q", snippet, expected)
+	}
+}
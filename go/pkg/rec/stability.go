@@ -0,0 +1,162 @@
+package rec
+
+import (
+	"math"
+	"strconv"
+	"vpr/pkg/types"
+	"vpr/pkg/utils"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// OutPathCsvLastRun is the path to the CSV file persisting the recommendations of the last run
+	OutPathCsvLastRun = types.DataPath + "last_run.csv"
+)
+
+// LoadLastRun loads the recommendations persisted by the previous run (used for hysteresis)
+func (r *Recommender) LoadLastRun() {
+	r.lastRun = make(map[string]Recommendation)
+	data, err := utils.ReadCSV(OutPathCsvLastRun)
+	if err != nil {
+		log.Info("No previous run found in ", OutPathCsvLastRun, ", hysteresis will start from next run")
+		return
+	}
+	for i, line := range data {
+		// omit header line
+		if i == 0 || len(line) < 6 {
+			continue
+		}
+		c := Recommendation{Namespace: line[0], PodGroupName: line[1], ContainerName: line[2]}
+		c.NewCPUReqM, _ = strconv.ParseFloat(line[3], 64)
+		c.NewMemReqMB, _ = strconv.ParseFloat(line[4], 64)
+		c.NewMemLimitMB, _ = strconv.ParseFloat(line[5], 64)
+		if len(line) > 6 {
+			c.Role = line[6]
+		}
+		r.lastRun[lastRunKey(c)] = c
+	}
+	log.Info("Loaded ", len(r.lastRun), " recommendations from previous run")
+}
+
+// SaveLastRun persists the recommendations of this run so that the next run can apply hysteresis
+func (r *Recommender) SaveLastRun(rec []Recommendation) {
+	csvData := [][]string{{"Namespace", "PodGroupName", "ContainerName", "NewCPUReqM", "NewMemReqMB", "NewMemLimitMB", "Role"}}
+	for _, elem := range rec {
+		csvData = append(csvData, []string{
+			elem.Namespace,
+			elem.PodGroupName,
+			elem.ContainerName,
+			strconv.FormatFloat(elem.NewCPUReqM, 'f', -1, 64),
+			strconv.FormatFloat(elem.NewMemReqMB, 'f', -1, 64),
+			strconv.FormatFloat(elem.NewMemLimitMB, 'f', -1, 64),
+			elem.Role,
+		})
+	}
+	utils.GenCSV(OutPathCsvLastRun, csvData)
+}
+
+func lastRunKey(c Recommendation) string {
+	if c.Role != "" {
+		return c.Namespace + "/" + c.PodGroupName + "[" + c.Role + "]/" + c.ContainerName
+	}
+	return c.Namespace + "/" + c.PodGroupName + "/" + c.ContainerName
+}
+
+// stepDown caps the decrease of each recommendation to a percentage of the current request/limit per run
+// the uncapped values are kept as targets and StepsLeft is the number of runs needed to reach them
+func (r *Recommender) stepDown(c *Recommendation, isJVM bool) {
+	cpuPercent, memPercent := r.MaxStepDownCPUPercent, r.MaxStepDownMemPercent
+	if isJVM {
+		cpuPercent, memPercent = r.MaxStepDownJVMCPUPercent, r.MaxStepDownJVMMemPercent
+	}
+	c.TargetCPUReqM = c.NewCPUReqM
+	c.TargetMemReqMB = c.NewMemReqMB
+	c.TargetMemLimitMB = c.NewMemLimitMB
+
+	var cpuSteps, memSteps, memLimitSteps int
+	c.NewCPUReqM, cpuSteps = capDecrease(c.CPUReqM, c.TargetCPUReqM, cpuPercent)
+	c.NewMemReqMB, memSteps = capDecrease(c.MemReqMB, c.TargetMemReqMB, memPercent)
+	c.NewMemLimitMB, memLimitSteps = capDecrease(c.MemLimitMB, c.TargetMemLimitMB, memPercent)
+	c.StepsLeft = int(math.Max(float64(cpuSteps), math.Max(float64(memSteps), float64(memLimitSteps))))
+}
+
+// capDecrease returns the next value towards target when the decrease from current is capped to percent %
+// and the number of steps (including the next one) to reach the target, 0 meaning the target is reached at once
+func capDecrease(current, target, percent float64) (float64, int) {
+	if percent <= 0 || percent >= 100 || current <= 0 || target >= current {
+		return target, 0
+	}
+	nextStep := current * (100.0 - percent) / 100.0
+	if target >= nextStep {
+		return target, 0
+	}
+	//each step keeps (100 - percent)% of the previous one
+	steps := math.Ceil(math.Log(math.Max(target, 1.0)/current) / math.Log((100.0-percent)/100.0))
+	return nextStep, int(steps)
+}
+
+// stabilize keeps the previous value when the new one is within the hysteresis band
+// otherwise rounds up the new value to the configured step so that reruns do not churn the helm values
+// the power of two rounding only applies to the memory (it could nearly double the CPU)
+// a value stepped down towards its target is rounded down to the step without hysteresis, otherwise the next step would be undone
+func (r *Recommender) stabilize(c *Recommendation, untouchMemoryLimit bool) {
+	prev, hasPrevious := r.lastRun[lastRunKey(*c)]
+	stepped := c.StepsLeft > 0
+
+	c.NewCPUReqM = r.stabilizeValue(c.NewCPUReqM, c.TargetCPUReqM, prev.NewCPUReqM, hasPrevious, stepped, r.CPURoundingStepM, false)
+	c.NewMemReqMB = r.stabilizeValue(c.NewMemReqMB, c.TargetMemReqMB, prev.NewMemReqMB, hasPrevious, stepped, r.MemRoundingStepMB, r.RoundingPowerOfTwo)
+	if !untouchMemoryLimit {
+		c.NewMemLimitMB = r.stabilizeValue(c.NewMemLimitMB, c.TargetMemLimitMB, prev.NewMemLimitMB, hasPrevious, stepped, r.MemRoundingStepMB, r.RoundingPowerOfTwo)
+	}
+	c.TargetCPUReqM = roundUp(c.TargetCPUReqM, r.CPURoundingStepM, false)
+	c.TargetMemReqMB = roundUp(c.TargetMemReqMB, r.MemRoundingStepMB, r.RoundingPowerOfTwo)
+	if !untouchMemoryLimit {
+		c.TargetMemLimitMB = roundUp(c.TargetMemLimitMB, r.MemRoundingStepMB, r.RoundingPowerOfTwo)
+	}
+	//a request kept by hysteresis must never exceed a limit which moved
+	if c.NewMemLimitMB > 0 && c.NewMemReqMB > c.NewMemLimitMB {
+		c.NewMemReqMB = c.NewMemLimitMB
+	}
+}
+
+func (r *Recommender) stabilizeValue(value, target, previous float64, hasPrevious, stepped bool, step float64, powerOfTwo bool) float64 {
+	if stepped && value > target {
+		return math.Max(roundDown(value, step), target)
+	}
+	if hasPrevious && withinHysteresis(previous, value, r.HysteresisPercent) {
+		return previous
+	}
+	return roundUp(value, step, powerOfTwo)
+}
+
+// withinHysteresis returns true if value is within percent % of previous
+func withinHysteresis(previous, value, percent float64) bool {
+	if previous <= 0 || percent <= 0 {
+		return false
+	}
+	return math.Abs(value-previous)*100.0/previous <= percent
+}
+
+// roundDown rounds down value to the previous multiple of step (a power of two would exceed the max step down)
+func roundDown(value, step float64) float64 {
+	if value <= 0 || step <= 0 {
+		return value
+	}
+	return math.Floor(value/step) * step
+}
+
+// roundUp rounds up value to the next multiple of step or to the next power of two
+// rounding up (and not to the nearest) avoids recommending less than what was computed
+func roundUp(value, step float64, powerOfTwo bool) float64 {
+	if value <= 0 {
+		return value
+	}
+	if powerOfTwo {
+		return math.Pow(2, math.Ceil(math.Log2(value)))
+	}
+	if step <= 0 {
+		return value
+	}
+	return math.Ceil(value/step) * step
+}
@@ -0,0 +1,166 @@
+package rec
+
+import (
+	"testing"
+)
+
+func TestRoundUp(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      float64
+		step       float64
+		powerOfTwo bool
+		expected   float64
+	}{
+		{"No step", 173.4, 0, false, 173.4},
+		{"CPU step 25m", 173.4, 25, false, 175},
+		{"Memory step 64Mi", 931.7, 64, false, 960},
+		{"Already on step", 960, 64, false, 960},
+		{"Power of two", 931.7, 0, true, 1024},
+		{"Power of two wins over step", 931.7, 64, true, 1024},
+		{"Zero value", 0, 64, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := roundUp(tt.value, tt.step, tt.powerOfTwo)
+			if result != tt.expected {
+				t.Errorf("roundUp(%v, %v, %v) = %v; want %v", tt.value, tt.step, tt.powerOfTwo, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStabilize(t *testing.T) {
+	r := &Recommender{
+		CPURoundingStepM:  25,
+		MemRoundingStepMB: 64,
+		HysteresisPercent: 5,
+		lastRun: map[string]Recommendation{
+			"ns/app/main": {NewCPUReqM: 175, NewMemReqMB: 960, NewMemLimitMB: 1152},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		input    Recommendation
+		untouch  bool
+		expected Recommendation
+	}{
+		{
+			name:     "Within hysteresis keeps previous values",
+			input:    Recommendation{Namespace: "ns", PodGroupName: "app", ContainerName: "main", NewCPUReqM: 171.2, NewMemReqMB: 931.7, NewMemLimitMB: 1170},
+			expected: Recommendation{NewCPUReqM: 175, NewMemReqMB: 960, NewMemLimitMB: 1152},
+		},
+		{
+			name:     "Outside hysteresis rounds new values",
+			input:    Recommendation{Namespace: "ns", PodGroupName: "app", ContainerName: "main", NewCPUReqM: 250.1, NewMemReqMB: 700, NewMemLimitMB: 800},
+			expected: Recommendation{NewCPUReqM: 275, NewMemReqMB: 704, NewMemLimitMB: 832},
+		},
+		{
+			name:     "No previous run only rounds",
+			input:    Recommendation{Namespace: "ns", PodGroupName: "other", ContainerName: "main", NewCPUReqM: 171.2, NewMemReqMB: 931.7, NewMemLimitMB: 1170},
+			expected: Recommendation{NewCPUReqM: 175, NewMemReqMB: 960, NewMemLimitMB: 1216},
+		},
+		{
+			name:     "Untouched memory limit is not rounded and caps the request",
+			input:    Recommendation{Namespace: "ns", PodGroupName: "app", ContainerName: "main", NewCPUReqM: 171.2, NewMemReqMB: 931.7, NewMemLimitMB: 900},
+			untouch:  true,
+			expected: Recommendation{NewCPUReqM: 175, NewMemReqMB: 900, NewMemLimitMB: 900},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.input
+			r.stabilize(&c, tt.untouch)
+			if c.NewCPUReqM != tt.expected.NewCPUReqM || c.NewMemReqMB != tt.expected.NewMemReqMB || c.NewMemLimitMB != tt.expected.NewMemLimitMB {
+				t.Errorf("stabilize() = {%v %v %v}; want {%v %v %v}", c.NewCPUReqM, c.NewMemReqMB, c.NewMemLimitMB, tt.expected.NewCPUReqM, tt.expected.NewMemReqMB, tt.expected.NewMemLimitMB)
+			}
+		})
+	}
+}
+
+func TestStabilizePowerOfTwoOnlyMemory(t *testing.T) {
+	r := &Recommender{CPURoundingStepM: 25, MemRoundingStepMB: 64, RoundingPowerOfTwo: true}
+	c := Recommendation{Namespace: "ns", PodGroupName: "app", ContainerName: "main", NewCPUReqM: 1100, NewMemReqMB: 931.7, NewMemLimitMB: 1170, TargetCPUReqM: 1090}
+	r.stabilize(&c, false)
+	if c.NewCPUReqM != 1100 || c.TargetCPUReqM != 1100 || c.NewMemReqMB != 1024 || c.NewMemLimitMB != 2048 {
+		t.Errorf("stabilize() = cpu %v target %v mem %v limit %v; want cpu 1100 target 1100 mem 1024 limit 2048", c.NewCPUReqM, c.TargetCPUReqM, c.NewMemReqMB, c.NewMemLimitMB)
+	}
+}
+
+func TestCapDecrease(t *testing.T) {
+	tests := []struct {
+		name          string
+		current       float64
+		target        float64
+		percent       float64
+		expectedValue float64
+		expectedSteps int
+	}{
+		{"No cap", 4096, 300, 0, 300, 0},
+		{"Increase is not capped", 300, 4096, 25, 4096, 0},
+		{"Decrease within cap", 1000, 800, 25, 800, 0},
+		{"Decrease capped 4Gi to 300Mi by 50%", 4096, 300, 50, 2048, 4},
+		{"Decrease capped by 25%", 1000, 500, 25, 750, 3},
+		{"No current value", 0, 300, 50, 300, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, steps := capDecrease(tt.current, tt.target, tt.percent)
+			if value != tt.expectedValue || steps != tt.expectedSteps {
+				t.Errorf("capDecrease(%v, %v, %v) = %v, %v; want %v, %v", tt.current, tt.target, tt.percent, value, steps, tt.expectedValue, tt.expectedSteps)
+			}
+		})
+	}
+}
+
+func TestStepDown(t *testing.T) {
+	r := &Recommender{MaxStepDownCPUPercent: 50, MaxStepDownMemPercent: 50, MaxStepDownJVMCPUPercent: 20, MaxStepDownJVMMemPercent: 10}
+	c := Recommendation{CPUReqM: 1000, MemReqMB: 4096, MemLimitMB: 4096, NewCPUReqM: 100, NewMemReqMB: 300, NewMemLimitMB: 400}
+
+	nonJVM := c
+	r.stepDown(&nonJVM, false)
+	if nonJVM.NewCPUReqM != 500 || nonJVM.NewMemReqMB != 2048 || nonJVM.NewMemLimitMB != 2048 {
+		t.Errorf("stepDown() non JVM = {%v %v %v}; want {500 2048 2048}", nonJVM.NewCPUReqM, nonJVM.NewMemReqMB, nonJVM.NewMemLimitMB)
+	}
+	if nonJVM.TargetCPUReqM != 100 || nonJVM.TargetMemReqMB != 300 || nonJVM.TargetMemLimitMB != 400 || nonJVM.StepsLeft != 4 {
+		t.Errorf("stepDown() non JVM targets = {%v %v %v} steps %v; want {100 300 400} steps 4", nonJVM.TargetCPUReqM, nonJVM.TargetMemReqMB, nonJVM.TargetMemLimitMB, nonJVM.StepsLeft)
+	}
+
+	jvm := c
+	r.stepDown(&jvm, true)
+	if jvm.NewCPUReqM != 800 || jvm.NewMemReqMB != 3686.4 || jvm.StepsLeft != 25 {
+		t.Errorf("stepDown() JVM = {%v %v} steps %v; want {800 3686.4} steps 25", jvm.NewCPUReqM, jvm.NewMemReqMB, jvm.StepsLeft)
+	}
+}
+
+func TestStepDownConsecutiveRuns(t *testing.T) {
+	//the max step down is below the hysteresis and the memory is rounded to a power of two
+	r := &Recommender{CPURoundingStepM: 25, RoundingPowerOfTwo: true, HysteresisPercent: 30, MaxStepDownCPUPercent: 25, MaxStepDownMemPercent: 20}
+	usage := Recommendation{Namespace: "ns", PodGroupName: "app", ContainerName: "main", NewCPUReqM: 100, NewMemReqMB: 300, NewMemLimitMB: 300}
+
+	//first run from the current requests
+	first := usage
+	first.CPUReqM, first.MemReqMB, first.MemLimitMB = 1000, 1024, 1024
+	r.stepDown(&first, false)
+	r.stabilize(&first, false)
+	if first.NewCPUReqM != 750 || first.NewMemReqMB != 819.2 || first.NewMemLimitMB != 819.2 {
+		t.Errorf("first run = {%v %v %v}; want {750 819.2 819.2}", first.NewCPUReqM, first.NewMemReqMB, first.NewMemLimitMB)
+	}
+
+	//second run once the first step is applied
+	r.lastRun = map[string]Recommendation{lastRunKey(first): first}
+	second := usage
+	second.CPUReqM, second.MemReqMB, second.MemLimitMB = first.NewCPUReqM, first.NewMemReqMB, first.NewMemLimitMB
+	r.stepDown(&second, false)
+	r.stabilize(&second, false)
+	if second.NewCPUReqM != 550 || second.NewMemReqMB >= first.NewMemReqMB || second.NewMemLimitMB >= first.NewMemLimitMB {
+		t.Errorf("second run = {%v %v %v}; want {550 <819.2 <819.2}", second.NewCPUReqM, second.NewMemReqMB, second.NewMemLimitMB)
+	}
+	if second.StepsLeft >= first.StepsLeft {
+		t.Errorf("second run steps left = %v; want less than %v", second.StepsLeft, first.StepsLeft)
+	}
+}
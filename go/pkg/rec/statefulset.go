@@ -0,0 +1,93 @@
+package rec
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"vpr/pkg/utils"
+
+	"github.com/prometheus/common/model"
+)
+
+const (
+	queryStsPodRoles = `max by (pod,$rolelabel)(kube_pod_labels{namespace=~"$namespace",pod=~"$podgroup$suffix"})`
+)
+
+// splitStatefulSets splits the StatefulSets per role (pod label) if StsRoleLabel is set, otherwise per ordinal if StsPerOrdinal is set
+// the other pod groups are kept as is
+func (r *Recommender) splitStatefulSets(podGroups []PodGroup) []PodGroup {
+	if r.StsRoleLabel == "" && !r.StsPerOrdinal {
+		return podGroups
+	}
+	result := []PodGroup{}
+	for _, podGroup := range podGroups {
+		if podGroup.Kind != sts {
+			result = append(result, podGroup)
+			continue
+		}
+		var split []PodGroup
+		if r.StsRoleLabel != "" {
+			split = r.splitPerRole(podGroup)
+		} else {
+			split = splitPerOrdinal(podGroup)
+		}
+		if len(split) == 0 {
+			result = append(result, podGroup)
+			continue
+		}
+		result = append(result, split...)
+	}
+	return result
+}
+
+// splitPerOrdinal returns a pod group per StatefulSet pod (ordinals 0 to replicas-1)
+func splitPerOrdinal(podGroup PodGroup) []PodGroup {
+	result := []PodGroup{}
+	for i := 0; i < podGroup.Count; i++ {
+		ordinal := strconv.Itoa(i)
+		result = append(result, PodGroup{Kind: podGroup.Kind, Name: podGroup.Name, Namespace: podGroup.Namespace, Suffix: "-" + ordinal, Count: 1, Role: ordinal})
+	}
+	return result
+}
+
+// splitPerRole returns a pod group per value of the role label of the StatefulSet pods
+func (r *Recommender) splitPerRole(podGroup PodGroup) []PodGroup {
+	result := []PodGroup{}
+	roleLabel := kubeStateLabel(r.StsRoleLabel)
+	nsVars := []utils.Var{{Name: "namespace", Value: podGroup.Namespace}, {Name: "podgroup", Value: podGroup.Name}, {Name: "suffix", Value: podGroup.Suffix}, {Name: "rolelabel", Value: roleLabel}}
+	ordinalsByRole := make(map[string][]string)
+	for _, elem := range r.queryVector(queryStsPodRoles, nsVars) {
+		role := string(elem.Metric[model.LabelName(roleLabel)])
+		ordinal := strings.TrimPrefix(string(elem.Metric["pod"]), podGroup.Name+"-")
+		if role == "" {
+			role = "none"
+		}
+		ordinalsByRole[role] = append(ordinalsByRole[role], ordinal)
+	}
+	for role, ordinals := range ordinalsByRole {
+		sort.Strings(ordinals)
+		result = append(result, PodGroup{Kind: podGroup.Kind, Name: podGroup.Name, Namespace: podGroup.Namespace, Suffix: "-(" + strings.Join(ordinals, "|") + ")", Count: len(ordinals), Role: role})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Role < result[j].Role })
+	return result
+}
+
+// splitAlias returns the limit alias of a StatefulSet split per role/ordinal, so that each split gets its own helm values entry
+// the role is the level 3 of a 2 levels alias (e.g. res.kafka.broker), appended to the level 2 of a 3 levels alias (e.g. res.kafka_broker.web)
+func splitAlias(alias, role string) string {
+	if role == "" || alias == "NA" {
+		return alias
+	}
+	key := strings.ReplaceAll(role, ".", "_")
+	if _, err := strconv.Atoi(key); err == nil {
+		key = "ordinal" + key
+	}
+	levels := strings.Split(alias, ".")
+	switch len(levels) {
+	case 2:
+		return alias + "." + key
+	case 3:
+		return levels[0] + "." + levels[1] + "_" + key + "." + levels[2]
+	}
+	return alias
+}
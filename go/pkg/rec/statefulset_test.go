@@ -0,0 +1,27 @@
+package rec
+
+import "testing"
+
+func TestSplitAlias(t *testing.T) {
+	tests := []struct {
+		name     string
+		alias    string
+		role     string
+		expected string
+	}{
+		{"Not split", "res.kafka", "", "res.kafka"},
+		{"Unknown alias", "NA", "broker", "NA"},
+		{"Role as level 3", "res.kafka", "broker", "res.kafka.broker"},
+		{"Ordinal as level 3", "res.kafka", "0", "res.kafka.ordinal0"},
+		{"Role appended to level 2 of a container alias", "res.kafka.web", "broker", "res.kafka_broker.web"},
+		{"Dots of the role replaced", "res.kafka", "eu.west", "res.kafka.eu_west"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if alias := splitAlias(tt.alias, tt.role); alias != tt.expected {
+				t.Errorf("splitAlias(%q, %q) = %q; want %q", tt.alias, tt.role, alias, tt.expected)
+			}
+		})
+	}
+}
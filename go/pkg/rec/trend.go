@@ -0,0 +1,79 @@
+package rec
+
+import (
+	"sort"
+
+	"github.com/prometheus/common/model"
+)
+
+const (
+	//the samples are downsampled to keep the Theil-Sen estimator (O(n²)) cheap
+	maxTrendPoints = 200
+	msPerDay       = 24 * 60 * 60 * 1000.0
+)
+
+type trendPoint struct {
+	Day   float64
+	Value float64
+}
+
+// trendSlope returns the robust growth of the samples per day using the Theil-Sen estimator
+// (median of the slopes between all pairs of points) so that GC sawtooth or spikes do not drive the trend
+func trendSlope(samples []model.SamplePair) float64 {
+	points := downsample(samples, maxTrendPoints)
+	if len(points) < 2 {
+		return 0
+	}
+	slopes := []float64{}
+	for i := 0; i < len(points)-1; i++ {
+		for j := i + 1; j < len(points); j++ {
+			if points[j].Day != points[i].Day {
+				slopes = append(slopes, (points[j].Value-points[i].Value)/(points[j].Day-points[i].Day))
+			}
+		}
+	}
+	return median(slopes)
+}
+
+// downsample splits the samples in at most n chunks and keeps the median of each chunk
+func downsample(samples []model.SamplePair, n int) []trendPoint {
+	points := []trendPoint{}
+	if len(samples) == 0 {
+		return points
+	}
+	chunkSize := (len(samples) + n - 1) / n
+	for start := 0; start < len(samples); start += chunkSize {
+		end := start + chunkSize
+		if end > len(samples) {
+			end = len(samples)
+		}
+		values := make([]float64, 0, end-start)
+		for _, sample := range samples[start:end] {
+			values = append(values, float64(sample.Value))
+		}
+		middle := samples[(start+end-1)/2].Timestamp
+		points = append(points, trendPoint{Day: float64(middle) / msPerDay, Value: median(values)})
+	}
+	return points
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// leakSuspected returns true if the growth over the history window is at least minGrowthPercent % of the baseline
+func leakSuspected(slopePerDay, baseline, historyDays, minGrowthPercent float64) bool {
+	if slopePerDay <= 0 || baseline <= 0 || minGrowthPercent <= 0 {
+		return false
+	}
+	return slopePerDay*historyDays*100.0/baseline >= minGrowthPercent
+}
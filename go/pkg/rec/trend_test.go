@@ -0,0 +1,71 @@
+package rec
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTrendSlope(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    func(t time.Time) float64
+		expected float64
+	}{
+		{
+			name:     "Flat usage",
+			value:    func(t time.Time) float64 { return 500 },
+			expected: 0,
+		},
+		{
+			name: "Steady growth of 100 MiB per day with a GC sawtooth",
+			value: func(t time.Time) float64 {
+				days := float64(t.Sub(time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC))) / float64(24*time.Hour)
+				return 1000 + 100*days + float64(t.Minute()%30)*5
+			},
+			expected: 100,
+		},
+		{
+			name: "Flat usage with a few spikes",
+			value: func(t time.Time) float64 {
+				if t.Hour() == 12 && t.Minute() < 20 {
+					return 5000
+				}
+				return 500
+			},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := trendSlope(genWeekSamples(1, tt.value))
+			if math.Abs(result-tt.expected) > 1 {
+				t.Errorf("trendSlope() = %v; want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLeakSuspected(t *testing.T) {
+	tests := []struct {
+		name        string
+		slope       float64
+		baseline    float64
+		historyDays float64
+		expected    bool
+	}{
+		{"Growing 100 MiB/day on 1000 MiB over 7 days", 100, 1000, 7, true},
+		{"Growing 10 MiB/day on 1000 MiB over 7 days", 10, 1000, 7, false},
+		{"Decreasing", -100, 1000, 7, false},
+		{"No baseline", 100, 0, 7, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := leakSuspected(tt.slope, tt.baseline, tt.historyDays, 20); got != tt.expected {
+				t.Errorf("leakSuspected(%v, %v, %v) = %v; want %v", tt.slope, tt.baseline, tt.historyDays, got, tt.expected)
+			}
+		})
+	}
+}
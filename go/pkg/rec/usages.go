@@ -1,6 +1,8 @@
 package rec
 
 import (
+	"math"
+	"sort"
 	"time"
 	"vpr/pkg/utils"
 
@@ -10,19 +12,26 @@ import (
 )
 
 const (
-	queryCPUUsage = `max by(container)(rate (container_cpu_usage_seconds_total{namespace=~"$namespace",pod=~"$podgroup$suffix",container!="",container!="POD"}[$interval])) * 1000`
-	queryMemUsage = `max by(container)(container_memory_working_set_bytes{namespace=~"$namespace",pod=~"$podgroup$suffix",container!="",container!="POD"}) / 1048576`
+	queryCPUUsage = `max by(pod,container)(rate (container_cpu_usage_seconds_total{namespace=~"$namespace",pod=~"$podgroup$suffix",container!="",container!="POD"}[$interval])) * 1000`
+	queryMemUsage = `max by(pod,container)(container_memory_working_set_bytes{namespace=~"$namespace",pod=~"$podgroup$suffix",container!="",container!="POD"}) / 1048576`
 )
 
 type containerUsage struct {
-	Name   string
-	Values Stats
+	Name        string
+	Values      Stats
+	StartupPeak float64
+	Pods        map[string][]model.SamplePair
 }
 
 // ContainerUsage is a struct with all containers CPU/mem usage
 type ContainerUsage struct {
 	CPUUsageM  Stats
 	MemUsageMB Stats
+	//max CPU during the warm-up after the container starts (excluded from CPUUsageM)
+	CPUStartupPeakM float64
+	//samples of each pod (the usage stats are the pointwise max across the pods)
+	CPUPodsM  map[string][]model.SamplePair
+	MemPodsMB map[string][]model.SamplePair
 }
 
 // Stats is a struct with useful stats
@@ -31,6 +40,27 @@ type Stats struct {
 	Mean       float64
 	Percentile float64
 	Max        float64
+	//robust growth per day (Theil-Sen)
+	SlopePerDay float64
+	//samples are kept to compute other percentiles (e.g. per limit alias)
+	samples []model.SamplePair
+}
+
+// PercentileAt returns the percentile of the samples at percent (or the default Percentile if no samples were kept)
+func (s Stats) PercentileAt(percent float64) float64 {
+	if len(s.samples) == 0 {
+		return s.Percentile
+	}
+	values := make([]float64, len(s.samples))
+	for i, sample := range s.samples {
+		values[i] = float64(sample.Value)
+	}
+	percentile, err := stats.Percentile(values, percent)
+	if err != nil {
+		log.Error("Error getting Percentile ", percent, " err ", err)
+		return s.Percentile
+	}
+	return percentile
 }
 
 // GetPodGroupUsage get cpu/mem usage historical for a pod group
@@ -38,32 +68,43 @@ func (r *Recommender) GetPodGroupUsage(namespace, podgroup, suffixKind string) m
 	result := make(map[string]ContainerUsage)
 	nsVars := []utils.Var{{Name: "namespace", Value: namespace}, {Name: "podgroup", Value: podgroup}, {Name: "suffix", Value: suffixKind}, {Name: "interval", Value: r.Interval.String()}}
 
-	cpuUsage := r.getContainerUsage(queryCPUUsage, nsVars)
-	memUsage := r.getContainerUsage(queryMemUsage, nsVars)
+	window := r.usageWindowFor(namespace, podgroup)
+
+	//the CPU burnt during the warm-up after each container start is excluded from the steady state
+	cpuWindow := window
+	if r.WarmupExclusion > 0 {
+		cpuWindow.Starts = r.getContainerStarts(nsVars, window)
+	}
+
+	cpuUsage := r.getContainerUsage(queryCPUUsage, nsVars, cpuWindow)
+	memUsage := r.getContainerUsage(queryMemUsage, nsVars, window)
 
 	for _, elem := range cpuUsage {
-		result[elem.Name] = ContainerUsage{CPUUsageM: elem.Values}
+		result[elem.Name] = ContainerUsage{CPUUsageM: elem.Values, CPUStartupPeakM: elem.StartupPeak, CPUPodsM: elem.Pods}
 	}
 	for _, elem := range memUsage {
 		if val, ok := result[elem.Name]; ok {
 			val.MemUsageMB = elem.Values
+			val.MemPodsMB = elem.Pods
 			result[elem.Name] = val
 		} else {
-			result[elem.Name] = ContainerUsage{MemUsageMB: elem.Values}
+			result[elem.Name] = ContainerUsage{MemUsageMB: elem.Values, MemPodsMB: elem.Pods}
 		}
 	}
 
 	return result
 }
 
-func (r *Recommender) getContainerUsage(query string, vars []utils.Var) []containerUsage {
+// getContainerUsage returns the stats per container of the pointwise max across the pods
+// along with the samples of each pod to detect the outlier pods
+func (r *Recommender) getContainerUsage(query string, vars []utils.Var, window usageWindow) []containerUsage {
 	result := []containerUsage{}
 	query, err := utils.SubstVars(query, vars)
 	if err != nil {
 		log.Error("Error subst Vars:", err)
 		return result
 	}
-	data, err := utils.PromQueryRange(r.PromURL, query, vars, time.Now().Add(-r.History), time.Now(), r.Interval)
+	data, err := utils.PromQueryRange(r.PromURL, query, vars, window.From, time.Now(), r.Interval)
 	if err != nil {
 		log.Error("PromQL Range query wrong for ", query, " err ", err)
 	} else {
@@ -72,13 +113,78 @@ func (r *Recommender) getContainerUsage(query string, vars []utils.Var) []contai
 			log.Error("Error converting to matrix for query ", query)
 			return result
 		}
+		mContainers := make(map[string]*containerUsage)
 		for _, elem := range matrixVal {
-			result = append(result, containerUsage{Name: string(elem.Metric["container"]), Values: r.GetStats(elem.Values, query)})
+			container := string(elem.Metric["container"])
+			pod := string(elem.Metric["pod"])
+			samples := r.excludeSamples(elem.Values, window.Exclusions)
+			if len(samples) == 0 {
+				log.Debug("All samples excluded for pod ", pod, " container ", container)
+				continue
+			}
+			samples, startupPeak := dropWarmup(samples, window.Starts[pod+"/"+container], r.WarmupExclusion)
+			usage, ok := mContainers[container]
+			if !ok {
+				usage = &containerUsage{Name: container, Pods: make(map[string][]model.SamplePair)}
+				mContainers[container] = usage
+			}
+			usage.StartupPeak = math.Max(usage.StartupPeak, startupPeak)
+			usage.Pods[pod] = samples
+		}
+		for _, usage := range mContainers {
+			series := make([][]model.SamplePair, 0, len(usage.Pods))
+			for _, samples := range usage.Pods {
+				series = append(series, samples)
+			}
+			usage.Values = r.GetStats(mergeMax(series), query)
+			result = append(result, *usage)
+		}
+	}
+	return result
+}
+
+// mergeMax returns the pointwise max of the series (e.g. across the pods of a container)
+func mergeMax(series [][]model.SamplePair) []model.SamplePair {
+	if len(series) == 1 {
+		return series[0]
+	}
+	maxByTime := make(map[model.Time]model.SampleValue)
+	for _, samples := range series {
+		for _, sample := range samples {
+			if value, ok := maxByTime[sample.Timestamp]; !ok || sample.Value > value {
+				maxByTime[sample.Timestamp] = sample.Value
+			}
 		}
 	}
+	result := make([]model.SamplePair, 0, len(maxByTime))
+	for timestamp, value := range maxByTime {
+		result = append(result, model.SamplePair{Timestamp: timestamp, Value: value})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Timestamp < result[j].Timestamp })
+	return result
+}
+
+// podPercentiles returns the percentile of each pod at percent
+func podPercentiles(pods map[string][]model.SamplePair, percent float64) map[string]float64 {
+	result := make(map[string]float64, len(pods))
+	for pod, samples := range pods {
+		result[pod] = percentileOf(samples, percent)
+	}
 	return result
 }
 
+func percentileOf(samples []model.SamplePair, percent float64) float64 {
+	values := make([]float64, len(samples))
+	for i, sample := range samples {
+		values[i] = float64(sample.Value)
+	}
+	percentile, err := stats.Percentile(values, percent)
+	if err != nil {
+		log.Error("Error getting Percentile ", percent, " err ", err)
+	}
+	return percentile
+}
+
 // GetStats get Stats from a []model.SamplePair
 func (r *Recommender) GetStats(samples []model.SamplePair, query string) Stats {
 	// Get the values
@@ -110,5 +216,5 @@ func (r *Recommender) GetStats(samples []model.SamplePair, query string) Stats {
 		log.Error("Error getting Max for query result ", query, " err ", err)
 	}
 
-	return Stats{Min: min, Mean: mean, Percentile: percentile, Max: max}
+	return Stats{Min: min, Mean: mean, Percentile: percentile, Max: max, SlopePerDay: trendSlope(samples), samples: samples}
 }
@@ -0,0 +1,66 @@
+package rec
+
+import (
+	"time"
+	"vpr/pkg/utils"
+
+	"github.com/prometheus/common/model"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	//start time (unix seconds) of each container, from kube-state-metrics or cadvisor
+	queryContainerStarts = `max by (pod,container)(kube_pod_container_state_started{namespace=~"$namespace",pod=~"$podgroup$suffix",container!=""})` +
+		` or max by (pod,container)(container_start_time_seconds{namespace=~"$namespace",pod=~"$podgroup$suffix",container!="",container!="POD"})`
+)
+
+// getContainerStarts returns the start times of each pod/container of a pod group during the data window
+func (r *Recommender) getContainerStarts(vars []utils.Var, window usageWindow) map[string][]time.Time {
+	result := make(map[string][]time.Time)
+	seen := make(map[string]bool)
+	for _, elem := range r.queryRangeMatrix(queryContainerStarts, vars, window.From, time.Now()) {
+		podContainer := string(elem.Metric["pod"]) + "/" + string(elem.Metric["container"])
+		for _, sample := range elem.Values {
+			start := time.Unix(int64(sample.Value), 0)
+			key := podContainer + "/" + start.String()
+			if seen[key] || start.Before(window.From) {
+				continue
+			}
+			seen[key] = true
+			result[podContainer] = append(result[podContainer], start)
+		}
+	}
+	return result
+}
+
+// dropWarmup drops the samples within warmup after any container start and returns the max of the dropped samples (startup peak)
+// all the samples are kept if the container never reaches a steady state
+func dropWarmup(samples []model.SamplePair, starts []time.Time, warmup time.Duration) ([]model.SamplePair, float64) {
+	if len(starts) == 0 || warmup <= 0 {
+		return samples, 0
+	}
+	result := make([]model.SamplePair, 0, len(samples))
+	peak := 0.0
+	for _, sample := range samples {
+		t := sample.Timestamp.Time()
+		inWarmup := false
+		for _, start := range starts {
+			if !t.Before(start) && t.Before(start.Add(warmup)) {
+				inWarmup = true
+				break
+			}
+		}
+		if inWarmup {
+			if float64(sample.Value) > peak {
+				peak = float64(sample.Value)
+			}
+		} else {
+			result = append(result, sample)
+		}
+	}
+	if len(result) == 0 {
+		log.Debug("All samples are within the warm-up, none dropped")
+		return samples, peak
+	}
+	return result, peak
+}
@@ -0,0 +1,44 @@
+package rec
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDropWarmup(t *testing.T) {
+	start := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	//steady state at 200m with a 3000m spike during the 5 first minutes after the starts at 0 and 60 min
+	samples := genSeries(start, 0, 120, 200)
+	for i := range samples {
+		if i%60 < 5 {
+			samples[i].Value = 3000
+		}
+	}
+
+	tests := []struct {
+		name         string
+		starts       []time.Time
+		warmup       time.Duration
+		expectedLen  int
+		expectedPeak float64
+	}{
+		{"No warm-up exclusion", []time.Time{start, start.Add(time.Hour)}, 0, 120, 0},
+		{"No container start", nil, 5 * time.Minute, 120, 0},
+		{"Warm-up of 5 min after each start", []time.Time{start, start.Add(time.Hour)}, 5 * time.Minute, 110, 3000},
+		{"Warm-up longer than the samples keeps them all", []time.Time{start}, 3 * time.Hour, 120, 3000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, peak := dropWarmup(samples, tt.starts, tt.warmup)
+			if len(result) != tt.expectedLen || peak != tt.expectedPeak {
+				t.Errorf("dropWarmup() = %v samples, peak %v; want %v samples, peak %v", len(result), peak, tt.expectedLen, tt.expectedPeak)
+			}
+			for _, sample := range result {
+				if tt.expectedLen == 110 && sample.Value != 200 {
+					t.Errorf("dropWarmup() kept a warm-up sample %v", sample)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package rec
+
+import (
+	"regexp"
+	"time"
+	"vpr/pkg/utils"
+
+	"github.com/prometheus/common/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// exclusionWindowsFor returns the indexes of the exclusion windows applying to a pod group over the history
+func (r *Recommender) exclusionWindowsFor(namespace, podgroup string) []int {
+	indexes := []int{}
+	from := time.Now().Add(-r.History)
+	for i, window := range r.ExclusionWindows {
+		if window.End.Before(from) {
+			continue
+		}
+		//^ is the start of the string, $ is the end of the string
+		matchNamespace, _ := regexp.MatchString("^"+window.Namespace+"$", namespace)
+		matchPodGroup, _ := regexp.MatchString("^"+window.PodGroup+"$", podgroup)
+		if (window.Namespace == "" || matchNamespace) && (window.PodGroup == "" || matchPodGroup) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// excludeSamples drops the samples inside the given exclusion windows and records the dropped counts for the run metadata
+func (r *Recommender) excludeSamples(samples []model.SamplePair, indexes []int) []model.SamplePair {
+	if len(indexes) == 0 {
+		return samples
+	}
+	windows := make([]utils.ExclusionWindow, len(indexes))
+	for i, index := range indexes {
+		windows[i] = r.ExclusionWindows[index]
+	}
+	result, dropped := filterSamples(samples, windows)
+	if r.excludedSamples == nil {
+		r.excludedSamples = make(map[int]int)
+	}
+	for i, index := range indexes {
+		r.excludedSamples[index] += dropped[i]
+	}
+	return result
+}
+
+// filterSamples returns the samples outside all the windows and the number of samples dropped by each window
+func filterSamples(samples []model.SamplePair, windows []utils.ExclusionWindow) ([]model.SamplePair, []int) {
+	dropped := make([]int, len(windows))
+	result := make([]model.SamplePair, 0, len(samples))
+	for _, sample := range samples {
+		t := sample.Timestamp.Time()
+		excluded := false
+		for i, window := range windows {
+			if !t.Before(window.Start) && t.Before(window.End) {
+				dropped[i]++
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			result = append(result, sample)
+		}
+	}
+	return result, dropped
+}
+
+// appliedExclusionWindows returns the exclusion windows which dropped samples during the run
+func (r *Recommender) appliedExclusionWindows() []appliedExclusionWindow {
+	result := []appliedExclusionWindow{}
+	for i, window := range r.ExclusionWindows {
+		if r.excludedSamples[i] > 0 {
+			result = append(result, appliedExclusionWindow{ExclusionWindow: window, DroppedSamples: r.excludedSamples[i]})
+		}
+	}
+	log.Debug("Applied exclusion windows ", result)
+	return result
+}
@@ -0,0 +1,65 @@
+package rec
+
+import (
+	"testing"
+	"time"
+	"vpr/pkg/utils"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestFilterSamples(t *testing.T) {
+	start := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	samples := []model.SamplePair{}
+	for i := 0; i < 10; i++ {
+		samples = append(samples, model.SamplePair{Timestamp: model.TimeFromUnixNano(start.Add(time.Duration(i) * time.Hour).UnixNano()), Value: model.SampleValue(i)})
+	}
+	windows := []utils.ExclusionWindow{
+		{Start: start.Add(2 * time.Hour), End: start.Add(4 * time.Hour), Reason: "load test"},
+		{Start: start.Add(3 * time.Hour), End: start.Add(6 * time.Hour), Reason: "incident"},
+	}
+
+	result, dropped := filterSamples(samples, windows)
+	if len(result) != 6 || result[2].Value != 6 {
+		t.Errorf("filterSamples() kept %v; want the samples 0,1,6,7,8,9", result)
+	}
+	if dropped[0] != 2 || dropped[1] != 2 {
+		t.Errorf("filterSamples() dropped %v; want [2 2]", dropped)
+	}
+}
+
+func TestExclusionWindowsFor(t *testing.T) {
+	now := time.Now()
+	r := &Recommender{
+		History: 7 * 24 * time.Hour,
+		ExclusionWindows: []utils.ExclusionWindow{
+			{Start: now.Add(-48 * time.Hour), End: now.Add(-47 * time.Hour)},
+			{Start: now.Add(-48 * time.Hour), End: now.Add(-47 * time.Hour), Namespace: "shop"},
+			{Start: now.Add(-48 * time.Hour), End: now.Add(-47 * time.Hour), Namespace: "shop", PodGroup: "cart.*"},
+			{Start: now.Add(-30 * 24 * time.Hour), End: now.Add(-29 * 24 * time.Hour)},
+		},
+	}
+
+	tests := []struct {
+		namespace, podgroup string
+		expected            []int
+	}{
+		{"shop", "cart-api", []int{0, 1, 2}},
+		{"shop", "checkout", []int{0, 1}},
+		{"monitoring", "grafana", []int{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namespace+"/"+tt.podgroup, func(t *testing.T) {
+			result := r.exclusionWindowsFor(tt.namespace, tt.podgroup)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("exclusionWindowsFor() = %v; want %v", result, tt.expected)
+			}
+			for i := range result {
+				if result[i] != tt.expected[i] {
+					t.Errorf("exclusionWindowsFor() = %v; want %v", result, tt.expected)
+				}
+			}
+		})
+	}
+}
@@ -2,8 +2,11 @@ package utils
 
 import (
 	"encoding/csv"
+	"fmt"
 	"os"
+	"regexp"
 	"strconv"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -24,6 +27,7 @@ func GenCSV(filePath string, data [][]string) {
 }
 
 // PodContainerExtraParams struct for pod container and limit alias
+// all the optional overrides are ignored when not set (0)
 type PodContainerExtraParams struct {
 	Pod                string
 	Container          string
@@ -31,10 +35,38 @@ type PodContainerExtraParams struct {
 	HelmValueFileName  string
 	UntouchMemoryLimit bool
 	ExtraMemoryMargin  int
+	//optional overrides of the global policy
+	CPUPercentile        float64
+	MemPercentile        float64
+	MemLimitToReqPercent float64
+	PodMinCPUMillicores  float64
+	PodMinMemoryMb       float64
+	JVMMinLimitMB        float64
+	JVMFloorLimitMB      float64
+	JVMFloorThresholdMB  float64
+	QoSClass             string
+	//optional hard bounds
+	MinCPUReqM    float64
+	MaxCPUReqM    float64
+	MinMemReqMB   float64
+	MaxMemReqMB   float64
+	MinMemLimitMB float64
+	MaxMemLimitMB float64
+	//team owning the container (cost reporting)
+	Team string
+	//helm values key path (from the values root) of the recommended JVM options (e.g. api.env.JAVA_TOOL_OPTIONS)
+	JVMOptionsKey string
+	//current JVM options under JVMOptionsKey, the recommended heap flags are merged into them
+	JVMOptions string
+	//helm values key path (from the values root) of the recommended runtime memory knob (e.g. gateway.env.GOMEMLIMIT)
+	RuntimeOptionsKey string
+	//current value under RuntimeOptionsKey, the other options are kept (e.g. NODE_OPTIONS)
+	RuntimeOptions string
 }
 
 // ReadLimitAliasCSVFile to read the container limit aliases from a CSV file
-// pod_name,container_name,limit_alias
+// pod_name,container_name,limit_alias,helm_value_filename,untouch_memory_limit,extra_memory_margin_per
+// followed by optional columns found by their header name (e.g. cpu_percentile,min_mem_limit_mb...)
 func ReadLimitAliasCSVFile() ([]PodContainerExtraParams, error) {
 	filename := "resources/container_limit_aliases.csv"
 	config := make([]PodContainerExtraParams, 0)
@@ -56,23 +88,347 @@ func ReadLimitAliasCSVFile() ([]PodContainerExtraParams, error) {
 		return nil, err
 	}
 
-	for _, record := range records {
-		if len(record) < 4 {
-			log.Warn("ReadLimitAliasCSVFile record has less than 4 fields, skipping: ", record)
+	header := make(map[string]int)
+	for i, record := range records {
+		if i == 0 && len(record) > 0 && record[0] == "pod_name" {
+			for j, name := range record {
+				header[name] = j
+			}
+			continue
+		}
+		if len(record) < 6 {
+			log.Warn("ReadLimitAliasCSVFile record has less than 6 fields, skipping: ", record)
 			continue
 		}
 		extraMemMargingPer, _ := strconv.Atoi(record[5])
 		// Create a new PodContainerLimitAlias struct and append it to the config slice
 		alias := PodContainerExtraParams{
-			Pod:                record[0],
-			Container:          record[1],
-			LimitAlias:         record[2],
-			HelmValueFileName:  record[3],
-			UntouchMemoryLimit: record[4] == "true",
-			ExtraMemoryMargin:  extraMemMargingPer,
+			Pod:                  record[0],
+			Container:            record[1],
+			LimitAlias:           record[2],
+			HelmValueFileName:    record[3],
+			UntouchMemoryLimit:   record[4] == "true",
+			ExtraMemoryMargin:    extraMemMargingPer,
+			CPUPercentile:        floatColumn(record, header, "cpu_percentile"),
+			MemPercentile:        floatColumn(record, header, "mem_percentile"),
+			MemLimitToReqPercent: floatColumn(record, header, "mem_limit_to_req_percent"),
+			PodMinCPUMillicores:  floatColumn(record, header, "pod_min_cpu_m"),
+			PodMinMemoryMb:       floatColumn(record, header, "pod_min_mem_mb"),
+			JVMMinLimitMB:        floatColumn(record, header, "jvm_min_limit_mb"),
+			JVMFloorLimitMB:      floatColumn(record, header, "jvm_floor_limit_mb"),
+			JVMFloorThresholdMB:  floatColumn(record, header, "jvm_floor_threshold_mb"),
+			QoSClass:             stringColumn(record, header, "qos_class"),
+			MinCPUReqM:           floatColumn(record, header, "min_cpu_req_m"),
+			MaxCPUReqM:           floatColumn(record, header, "max_cpu_req_m"),
+			MinMemReqMB:          floatColumn(record, header, "min_mem_req_mb"),
+			MaxMemReqMB:          floatColumn(record, header, "max_mem_req_mb"),
+			MinMemLimitMB:        floatColumn(record, header, "min_mem_limit_mb"),
+			MaxMemLimitMB:        floatColumn(record, header, "max_mem_limit_mb"),
+			Team:                 stringColumn(record, header, "team"),
+			JVMOptionsKey:        stringColumn(record, header, "jvm_options_key"),
+			JVMOptions:           stringColumn(record, header, "jvm_options"),
+			RuntimeOptionsKey:    stringColumn(record, header, "runtime_options_key"),
+			RuntimeOptions:       stringColumn(record, header, "runtime_options"),
 		}
 		config = append(config, alias)
 	}
 
 	return config, nil
 }
+
+// stringColumn returns the value of an optional column found by its header name ("" if absent)
+func stringColumn(record []string, header map[string]int, name string) string {
+	if i, ok := header[name]; ok && i < len(record) {
+		return record[i]
+	}
+	return ""
+}
+
+// floatColumn returns the value of an optional float column found by its header name (0 if absent)
+func floatColumn(record []string, header map[string]int, name string) float64 {
+	value := stringColumn(record, header, name)
+	if value == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Warn("ReadLimitAliasCSVFile column ", name, " is not a number: ", value)
+		return 0
+	}
+	return f
+}
+
+// ReadCSV to read CSV files
+func ReadCSV(filePath string) ([][]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return csv.NewReader(file).ReadAll()
+}
+
+// ExclusionWindow is a time window whose samples are dropped (incident, load test, maintenance)
+// Namespace and PodGroup are regexes, empty means all (a global window)
+type ExclusionWindow struct {
+	Start     time.Time `json:"start" yaml:"start"`
+	End       time.Time `json:"end" yaml:"end"`
+	Namespace string    `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	PodGroup  string    `json:"pod_group,omitempty" yaml:"pod_group,omitempty"`
+	Reason    string    `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// ExclusionWindowsFile is the CSV file of the exclusion windows
+const ExclusionWindowsFile = "resources/exclusion_windows.csv"
+
+var exclusionWindowsHeader = []string{"start", "end", "namespace", "pod_group", "reason"}
+
+// Validate checks the window bounds and regexes
+func (w ExclusionWindow) Validate() error {
+	if w.Start.IsZero() || w.End.IsZero() || !w.End.After(w.Start) {
+		return fmt.Errorf("exclusion window end %s must be after start %s", w.End, w.Start)
+	}
+	if _, err := regexp.Compile(w.Namespace); err != nil {
+		return fmt.Errorf("exclusion window namespace regex %s is invalid: %w", w.Namespace, err)
+	}
+	if _, err := regexp.Compile(w.PodGroup); err != nil {
+		return fmt.Errorf("exclusion window pod group regex %s is invalid: %w", w.PodGroup, err)
+	}
+	return nil
+}
+
+// ReadExclusionWindowsCSVFile to read the exclusion windows from a CSV file (optional)
+// start,end,namespace,pod_group,reason with start and end in RFC3339
+func ReadExclusionWindowsCSVFile() ([]ExclusionWindow, error) {
+	windows := make([]ExclusionWindow, 0)
+	records, err := ReadCSV(ExclusionWindowsFile)
+	if os.IsNotExist(err) {
+		return windows, nil
+	}
+	if err != nil {
+		log.Error("ReadExclusionWindowsCSVFile error reading file ", ExclusionWindowsFile, " err ", err)
+		return windows, err
+	}
+	for i, record := range records {
+		if i == 0 && len(record) > 0 && record[0] == "start" {
+			continue
+		}
+		if len(record) < 2 {
+			log.Warn("ReadExclusionWindowsCSVFile record has less than 2 fields, skipping: ", record)
+			continue
+		}
+		start, errStart := time.Parse(time.RFC3339, record[0])
+		end, errEnd := time.Parse(time.RFC3339, record[1])
+		if errStart != nil || errEnd != nil {
+			log.Warn("ReadExclusionWindowsCSVFile start/end are not RFC3339, skipping: ", record)
+			continue
+		}
+		window := ExclusionWindow{Start: start, End: end}
+		if len(record) > 2 {
+			window.Namespace = record[2]
+		}
+		if len(record) > 3 {
+			window.PodGroup = record[3]
+		}
+		if len(record) > 4 {
+			window.Reason = record[4]
+		}
+		if err := window.Validate(); err != nil {
+			log.Warn("ReadExclusionWindowsCSVFile skipping record: ", err)
+			continue
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+// AppendExclusionWindowCSVFile to append an exclusion window to the CSV file (created with its header if absent)
+func AppendExclusionWindowCSVFile(window ExclusionWindow) error {
+	_, err := os.Stat(ExclusionWindowsFile)
+	newFile := os.IsNotExist(err)
+	file, err := os.OpenFile(ExclusionWindowsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	csvwriter := csv.NewWriter(file)
+	if newFile {
+		_ = csvwriter.Write(exclusionWindowsHeader)
+	}
+	_ = csvwriter.Write([]string{window.Start.Format(time.RFC3339), window.End.Format(time.RFC3339), window.Namespace, window.PodGroup, window.Reason})
+	csvwriter.Flush()
+	return csvwriter.Error()
+}
+
+// InstanceType is a node shape of the bin-packing simulation (allocatable CPU/memory and hourly price)
+type InstanceType struct {
+	Name      string
+	CPUM      float64
+	MemMB     float64
+	PriceHour float64
+}
+
+// ReadInstanceTypesCSVFile to read the instance types catalogue from a CSV file
+// name,cpu_m,mem_mb,price_hour
+func ReadInstanceTypesCSVFile() ([]InstanceType, error) {
+	filename := "resources/instance_types.csv"
+	instanceTypes := make([]InstanceType, 0)
+	records, err := ReadCSV(filename)
+	if err != nil {
+		log.Error("ReadInstanceTypesCSVFile error reading file ", filename, " err ", err)
+		return instanceTypes, err
+	}
+	for i, record := range records {
+		if i == 0 && len(record) > 0 && record[0] == "name" {
+			continue
+		}
+		if len(record) < 4 {
+			log.Warn("ReadInstanceTypesCSVFile record has less than 4 fields, skipping: ", record)
+			continue
+		}
+		cpuM, errCPU := strconv.ParseFloat(record[1], 64)
+		memMB, errMem := strconv.ParseFloat(record[2], 64)
+		priceHour, errPrice := strconv.ParseFloat(record[3], 64)
+		if errCPU != nil || errMem != nil || errPrice != nil || cpuM <= 0 || memMB <= 0 {
+			log.Warn("ReadInstanceTypesCSVFile record has invalid numbers, skipping: ", record)
+			continue
+		}
+		instanceTypes = append(instanceTypes, InstanceType{Name: record[0], CPUM: cpuM, MemMB: memMB, PriceHour: priceHour})
+	}
+	return instanceTypes, nil
+}
+
+// Price overrides the price per vCPU-hour and GiB-hour for a namespace (regex) or a node pool
+type Price struct {
+	Scope    string
+	Name     string
+	VCPUHour float64
+	GiBHour  float64
+}
+
+// ReadPricesCSVFile to read the price overrides from a CSV file (optional)
+// scope,name,price_vcpu_hour,price_gib_hour with scope namespace or node_pool
+func ReadPricesCSVFile() ([]Price, error) {
+	filename := "resources/prices.csv"
+	prices := make([]Price, 0)
+	records, err := ReadCSV(filename)
+	if os.IsNotExist(err) {
+		return prices, nil
+	}
+	if err != nil {
+		log.Error("ReadPricesCSVFile error reading file ", filename, " err ", err)
+		return prices, err
+	}
+	for i, record := range records {
+		if i == 0 && len(record) > 0 && record[0] == "scope" {
+			continue
+		}
+		if len(record) < 4 || (record[0] != "namespace" && record[0] != "node_pool") {
+			log.Warn("ReadPricesCSVFile record is not a namespace or node_pool price, skipping: ", record)
+			continue
+		}
+		vcpuHour, errCPU := strconv.ParseFloat(record[2], 64)
+		gibHour, errMem := strconv.ParseFloat(record[3], 64)
+		if errCPU != nil || errMem != nil {
+			log.Warn("ReadPricesCSVFile record has invalid prices, skipping: ", record)
+			continue
+		}
+		prices = append(prices, Price{Scope: record[0], Name: record[1], VCPUHour: vcpuHour, GiBHour: gibHour})
+	}
+	return prices, nil
+}
+
+// GCPool maps a JVM heap pool to its role (young, old or heap for a unified heap)
+// InMax is false for a pool whose max is not its own (e.g. ZGC generational young and old max are both the heap max)
+type GCPool struct {
+	Collector string
+	Pool      string
+	Role      string
+	InMax     bool
+}
+
+// ReadGCPoolsCSVFile to read the GC pools mapping from a CSV file (optional, overrides the built-in pools)
+// collector,pool,role,in_max with role young, old or heap
+func ReadGCPoolsCSVFile() ([]GCPool, error) {
+	filename := "resources/gc_pools.csv"
+	pools := make([]GCPool, 0)
+	records, err := ReadCSV(filename)
+	if os.IsNotExist(err) {
+		return pools, nil
+	}
+	if err != nil {
+		log.Error("ReadGCPoolsCSVFile error reading file ", filename, " err ", err)
+		return pools, err
+	}
+	for i, record := range records {
+		if i == 0 && len(record) > 0 && record[0] == "collector" {
+			continue
+		}
+		if len(record) < 4 || (record[2] != "young" && record[2] != "old" && record[2] != "heap") {
+			log.Warn("ReadGCPoolsCSVFile record is not a young, old or heap pool, skipping: ", record)
+			continue
+		}
+		inMax, err := strconv.ParseBool(record[3])
+		if err != nil {
+			log.Warn("ReadGCPoolsCSVFile record has an invalid in_max, skipping: ", record)
+			continue
+		}
+		pools = append(pools, GCPool{Collector: record[0], Pool: record[1], Role: record[2], InMax: inMax})
+	}
+	return pools, nil
+}
+
+// JVMSignal is a JVM health signal: a PromQL template matched to the containers by a label
+// it fires above its threshold and may keep (no-shrink) or grow (grow by EffectValue %) the heap
+type JVMSignal struct {
+	Name        string
+	Query       string
+	MatchLabel  string
+	MatchMode   string
+	Threshold   float64
+	Effect      string
+	EffectValue float64
+}
+
+// ReadJVMSignalsCSVFile to read the JVM health signals from a CSV file (optional)
+// name,query,match_label,match_mode,threshold,effect,effect_value with match_mode container, contains or all
+// and effect report, no-shrink or grow
+func ReadJVMSignalsCSVFile() ([]JVMSignal, error) {
+	filename := "resources/jvm_signals.csv"
+	signals := make([]JVMSignal, 0)
+	records, err := ReadCSV(filename)
+	if os.IsNotExist(err) {
+		return signals, nil
+	}
+	if err != nil {
+		log.Error("ReadJVMSignalsCSVFile error reading file ", filename, " err ", err)
+		return signals, err
+	}
+	for i, record := range records {
+		if i == 0 && len(record) > 0 && record[0] == "name" {
+			continue
+		}
+		if len(record) < 7 || record[0] == "" || record[1] == "" {
+			log.Warn("ReadJVMSignalsCSVFile record has less than 7 fields, skipping: ", record)
+			continue
+		}
+		if record[3] != "container" && record[3] != "contains" && record[3] != "all" {
+			log.Warn("ReadJVMSignalsCSVFile match_mode is not container, contains or all, skipping: ", record)
+			continue
+		}
+		if record[5] != "report" && record[5] != "no-shrink" && record[5] != "grow" {
+			log.Warn("ReadJVMSignalsCSVFile effect is not report, no-shrink or grow, skipping: ", record)
+			continue
+		}
+		threshold, errThreshold := strconv.ParseFloat(record[4], 64)
+		effectValue, errValue := strconv.ParseFloat(record[6], 64)
+		if errThreshold != nil || (errValue != nil && record[6] != "") {
+			log.Warn("ReadJVMSignalsCSVFile record has invalid numbers, skipping: ", record)
+			continue
+		}
+		signals = append(signals, JVMSignal{Name: record[0], Query: record[1], MatchLabel: record[2], MatchMode: record[3], Threshold: threshold, Effect: record[5], EffectValue: effectValue})
+	}
+	return signals, nil
+}
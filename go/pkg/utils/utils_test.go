@@ -39,3 +39,18 @@ func TestEnv(t *testing.T) {
 		t.Errorf("TestEnv = %t, want %t", gotBool, wantBool)
 	}
 }
+
+func TestFloatColumn(t *testing.T) {
+	header := map[string]int{"pod_name": 0, "cpu_percentile": 6, "mem_percentile": 7}
+	record := []string{"pod", "container", "res.pod", "", "", "", "99", ""}
+
+	if got := floatColumn(record, header, "cpu_percentile"); got != 99 {
+		t.Errorf("floatColumn(cpu_percentile) = %v, want 99", got)
+	}
+	if got := floatColumn(record, header, "mem_percentile"); got != 0 {
+		t.Errorf("floatColumn(mem_percentile) = %v, want 0", got)
+	}
+	if got := floatColumn(record, header, "unknown"); got != 0 {
+		t.Errorf("floatColumn(unknown) = %v, want 0", got)
+	}
+}